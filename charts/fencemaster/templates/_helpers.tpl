@@ -69,3 +69,32 @@ Image name
 {{- $tag := default .Chart.AppVersion .Values.image.tag }}
 {{- printf "%s:%s" .Values.image.repository $tag }}
 {{- end }}
+
+{{/*
+Whether the webhook server terminates TLS itself
+*/}}
+{{- define "fencemaster.tlsEnabled" -}}
+{{- if or .Values.webhook.tls.enabled .Values.webhook.tls.selfManaged }}true{{- end }}
+{{- end }}
+
+{{/*
+Per-cluster source overrides as "cluster=source" pairs
+*/}}
+{{- define "fencemaster.clusterSources" -}}
+{{- $pairs := list }}
+{{- range $cluster, $source := .Values.webhook.clusterSources }}
+{{- $pairs = append $pairs (printf "%s=%s" $cluster $source) }}
+{{- end }}
+{{- join "," $pairs }}
+{{- end }}
+
+{{/*
+Per-cluster default projects as "cluster=project" pairs
+*/}}
+{{- define "fencemaster.clusterDefaultProjects" -}}
+{{- $pairs := list }}
+{{- range $cluster, $project := .Values.webhook.clusterDefaultProjects }}
+{{- $pairs = append $pairs (printf "%s=%s" $cluster $project) }}
+{{- end }}
+{{- join "," $pairs }}
+{{- end }}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/reconciler"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// backfillCommand is the subcommand that assigns the existing namespaces of
+// downstream clusters once, e.g. when migrating clusters that predate fencemaster
+const backfillCommand = "backfill"
+
+// backfillOptions are the flags of the backfill subcommand. It also takes the
+// server's flags, so namespaces are assigned exactly as the webhook would.
+type backfillOptions struct {
+	kubeconfig        string
+	clusters          string
+	allClusters       bool
+	clusterKubeconfig string
+}
+
+// register adds the backfill flags to fs
+func (o *backfillOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Kubeconfig of the Rancher management cluster (default: $KUBECONFIG, ~/.kube/config or the in-cluster config)")
+	fs.StringVar(&o.clusters, "cluster", "", "Comma-separated names or IDs of the downstream clusters to backfill")
+	fs.BoolVar(&o.allClusters, "all-clusters", false, "Backfill all downstream clusters")
+	fs.StringVar(&o.clusterKubeconfig, "cluster-kubeconfig", "", "Kubeconfig of the downstream cluster given with --cluster (default: its kubeconfig Secret, or the cluster proxy with --rancher-url)")
+}
+
+// validate checks that the options select clusters consistently, and returns them
+func (o *backfillOptions) validate() ([]string, error) {
+	var clusters []string
+	for _, cluster := range strings.Split(o.clusters, ",") {
+		if cluster = strings.TrimSpace(cluster); cluster != "" {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	switch {
+	case len(clusters) == 0 && !o.allClusters:
+		return nil, fmt.Errorf("one of --cluster or --all-clusters is required")
+	case len(clusters) > 0 && o.allClusters:
+		return nil, fmt.Errorf("--cluster and --all-clusters are mutually exclusive")
+	case o.clusterKubeconfig != "" && len(clusters) != 1:
+		return nil, fmt.Errorf("--cluster-kubeconfig requires exactly one --cluster")
+	}
+	return clusters, nil
+}
+
+// restConfig returns the config of the management cluster
+func (o *backfillOptions) restConfig() (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+// connector returns a connector to the downstream cluster given with
+// --cluster-kubeconfig, or nil when clusters are reached as by the reconciler
+func (o *backfillOptions) connector() (reconciler.Connector, error) {
+	if o.clusterKubeconfig == "" {
+		return nil, nil
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", o.clusterKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load --cluster-kubeconfig: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client from --cluster-kubeconfig: %w", err)
+	}
+	return reconciler.NewStaticConnector(client), nil
+}
+
+// backfillMode is the reconcile mode of a backfill. A dry-run only reports,
+// which neither patches namespaces nor creates projects.
+func backfillMode(dryRun bool) reconciler.Mode {
+	if dryRun {
+		return reconciler.ModeReport
+	}
+	return reconciler.ModePatch
+}
+
+// runBackfill reconciles the selected clusters once and writes the namespaces
+// that needed changes to out. In dry-run mode nothing is written. It returns
+// the process exit code: 1 when a cluster or namespace failed.
+func runBackfill(ctx context.Context, rec *reconciler.Reconciler, dryRun bool, out io.Writer, logger *slog.Logger) int {
+	clusters, err := rec.Clusters(ctx)
+	if err != nil {
+		logger.Error("Failed to list clusters", slog.String("error", err.Error()))
+		return 1
+	}
+	if len(clusters) == 0 {
+		logger.Error("No matching clusters found")
+		return 1
+	}
+
+	exitCode := 0
+	var results []reconciler.Result
+	for _, cluster := range clusters {
+		clusterResults, err := rec.ReconcileCluster(ctx, cluster)
+		if err != nil {
+			logger.Error("Failed to backfill cluster",
+				slog.String("cluster", cluster.Name),
+				slog.String("cluster_id", cluster.ID),
+				slog.String("error", err.Error()),
+			)
+			exitCode = 1
+		}
+		results = append(results, clusterResults...)
+	}
+
+	if err := reconciler.WriteTable(out, results); err != nil {
+		logger.Error("Failed to write results", slog.String("error", err.Error()))
+		return 1
+	}
+
+	counts := reconciler.Count(results)
+	changed := fmt.Sprintf("%d patched", counts[reconciler.ActionPatched])
+	if dryRun {
+		changed = fmt.Sprintf("%d to patch", counts[reconciler.ActionDrift])
+	}
+	fmt.Fprintf(out, "\n%d namespaces in %d clusters: %d in sync, %s, %d skipped, %d denied, %d failed\n",
+		len(results), len(clusters), counts[reconciler.ActionInSync], changed,
+		counts[reconciler.ActionSkipped], counts[reconciler.ActionDenied], counts[reconciler.ActionFailed])
+
+	if counts[reconciler.ActionFailed] > 0 {
+		exitCode = 1
+	}
+	return exitCode
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	"github.com/rvbsalgado/fencemaster/pkg/reconciler"
+	"github.com/rvbsalgado/fencemaster/pkg/webhook"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRunBackfill_DryRunMakesNoWrites(t *testing.T) {
+	cluster := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "provisioning.cattle.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]any{"name": "prod", "namespace": "fleet-default"},
+		"status":     map[string]any{"clusterName": "c-m-abc123"},
+	}}
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, cluster)
+	dynamicClient.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		t.Errorf("unexpected create in dry-run: %v", action)
+		return true, nil, errors.New("dry-run backfills can't create anything")
+	})
+
+	clientset := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments-api", Labels: map[string]string{"project": "payments"}},
+	})
+	clientset.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetVerb() == "get" || action.GetVerb() == "list" {
+			return false, nil, nil
+		}
+		t.Errorf("unexpected %s in dry-run: %v", action.GetVerb(), action)
+		return true, nil, errors.New("dry-run backfills can't write")
+	})
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rancherClient := rancher.NewClient(dynamicClient, logger, rancher.ClientConfig{})
+	handler := webhook.NewHandler(rancherClient, logger, webhook.HandlerConfig{
+		ProjectLabel:      "project",
+		ProjectAnnotation: "field.cattle.io/projectId",
+		CreateProjects:    true,
+	})
+	rec := reconciler.New(handler, rancherClient, reconciler.NewStaticConnector(clientset), logger, reconciler.Config{
+		Mode:              backfillMode(true),
+		ProjectAnnotation: "field.cattle.io/projectId",
+	})
+
+	var out bytes.Buffer
+	if code := runBackfill(context.Background(), rec, true, &out, logger); code != 0 {
+		t.Fatalf("expected exit code 0, got %d:\n%s", code, out.String())
+	}
+	if !strings.Contains(out.String(), "would create project payments") || !strings.Contains(out.String(), "1 to patch") {
+		t.Errorf("expected the missing project to be reported, got:\n%s", out.String())
+	}
+}
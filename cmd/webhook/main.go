@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -14,10 +15,15 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rvbsalgado/fencemaster/pkg/auth"
+	"github.com/rvbsalgado/fencemaster/pkg/certs"
 	"github.com/rvbsalgado/fencemaster/pkg/logging"
 	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	"github.com/rvbsalgado/fencemaster/pkg/reconciler"
 	"github.com/rvbsalgado/fencemaster/pkg/webhook"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
@@ -33,9 +39,42 @@ func main() {
 		strictMode         bool
 		dryRun             bool
 		cacheTTLMins       int
+		negativeTTLSecs    int
+		useInformers       bool
+		fleetWorkspaces    string
+		clusterSource      string
+		clusterSources     string
 		projectLabel       string
 		projectAnnotation  string
 		excludeNamespaces  string
+		conflictPolicy     string
+		labelRemoval       string
+		defaultProject     string
+		clusterDefaults    string
+		projectRulesFile   string
+		projectSources     string
+		trackingFile       string
+		projectAccessFile  string
+		protectedProjects  string
+		createProjects     bool
+		projectTemplate    string
+		tlsCertFile        string
+		tlsKeyFile         string
+		selfManagedCerts   bool
+		certSecretName     string
+		serviceName        string
+		podNamespace       string
+		webhookConfigName  string
+		clientCAFile       string
+		clientTokensFile   string
+		reconcileMins      int
+		reconcileMode      string
+		reconcileClusters  string
+		reconcileUser      string
+		reconcileLease     string
+		rancherURL         string
+		rancherTokenFile   string
+		rancherCAFile      string
 	)
 
 	// Default excluded namespaces: system namespaces that should never be mutated
@@ -48,11 +87,54 @@ func main() {
 	flag.BoolVar(&strictMode, "strict-mode", getEnvBool("STRICT_MODE", false), "Reject namespace if project not found (default: allow without annotation)")
 	flag.BoolVar(&dryRun, "dry-run", getEnvBool("DRY_RUN", false), "Log what would happen without actually patching namespaces")
 	flag.IntVar(&cacheTTLMins, "cache-ttl", getEnvInt("CACHE_TTL_MINUTES", 5), "Cache TTL in minutes for cluster/project lookups")
+	flag.IntVar(&negativeTTLSecs, "negative-cache-ttl", getEnvInt("NEGATIVE_CACHE_TTL_SECONDS", 30), "Cache TTL in seconds for cluster/project not-found lookups (0 disables)")
+	flag.BoolVar(&useInformers, "informers", getEnvBool("USE_INFORMERS", true), "Watch clusters and projects and serve lookups from an in-memory index (requires watch permission)")
+	flag.StringVar(&fleetWorkspaces, "fleet-workspaces", getEnv("FLEET_WORKSPACES", rancher.DefaultFleetWorkspace), "Comma-separated list of Fleet workspaces to look clusters up in ('*' for all namespaces)")
+	flag.StringVar(&clusterSource, "cluster-source", getEnv("CLUSTER_SOURCE", string(rancher.ClusterSourceProvisioning)), "Resource to resolve cluster names from: provisioning, management, or auto (provisioning, then management clusters)")
+	flag.StringVar(&clusterSources, "cluster-sources", getEnv("CLUSTER_SOURCES", ""), "Comma-separated per-cluster overrides of --cluster-source (e.g. 'legacy-a=management')")
 	flag.StringVar(&projectLabel, "project-label", getEnv("PROJECT_LABEL", "project"), "Namespace label to read project name from")
 	flag.StringVar(&projectAnnotation, "project-annotation", getEnv("PROJECT_ANNOTATION", "field.cattle.io/projectId"), "Annotation key to set on namespace")
+	flag.StringVar(&conflictPolicy, "conflict-policy", getEnv("CONFLICT_POLICY", string(webhook.ConflictLabelWins)), "What to do when a project annotation set outside fencemaster disagrees with the project label: label-wins, annotation-wins or deny")
+	flag.StringVar(&labelRemoval, "label-removal-policy", getEnv("LABEL_REMOVAL_POLICY", string(webhook.LabelRemovalKeep)), "What to do when the project label is removed: keep (stay in the project), remove (unassign) or default (move to --default-project)")
+	flag.StringVar(&defaultProject, "default-project", getEnv("DEFAULT_PROJECT", ""), "Project unlabeled namespaces are assigned to, and namespaces move to when their project label is removed and --label-removal-policy=default")
+	flag.StringVar(&projectSources, "project-sources", getEnv("PROJECT_SOURCES", ""), "Comma-separated, ordered project sources: label:KEY, annotation:KEY or tracking:KEY (default: label:<project-label>)")
+	flag.StringVar(&trackingFile, "tracking-projects-file", getEnv("TRACKING_PROJECTS_FILE", ""), "YAML file mapping tracking label values to projects for tracking: project sources")
+	flag.StringVar(&projectRulesFile, "project-rules-file", getEnv("PROJECT_RULES_FILE", ""), "YAML file of namespace name rules (regex to project) for namespaces without a project label")
+	flag.StringVar(&projectAccessFile, "project-access-file", getEnv("PROJECT_ACCESS_FILE", ""), "YAML policy of the users, groups and service accounts allowed to assign namespaces to each project (default: anyone)")
+	flag.StringVar(&protectedProjects, "protected-projects", getEnv("PROTECTED_PROJECTS", "System"), "Comma-separated project display names or IDs that namespaces cannot be assigned to by label (rejected in strict mode, skipped otherwise)")
+	flag.BoolVar(&createProjects, "create-projects", getEnvBool("CREATE_PROJECTS", false), "Create projects that don't exist yet instead of leaving namespaces unassigned (requires create permission on projects)")
+	flag.StringVar(&projectTemplate, "project-template-file", getEnv("PROJECT_TEMPLATE_FILE", ""), "YAML file with the description, resource quotas and container limits of created projects")
+	flag.StringVar(&clusterDefaults, "cluster-default-projects", getEnv("CLUSTER_DEFAULT_PROJECTS", ""), "Comma-separated per-cluster overrides of --default-project (e.g. 'dev-cluster=sandbox')")
 	flag.StringVar(&excludeNamespaces, "exclude-namespaces", getEnv("EXCLUDE_NAMESPACES", defaultExclusions), "Comma-separated list of namespaces to exclude (supports * suffix for prefix matching)")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", getEnv("TLS_CERT_FILE", ""), "Path to the TLS certificate file (enables HTTPS on the webhook server)")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", getEnv("TLS_KEY_FILE", ""), "Path to the TLS private key file")
+	flag.BoolVar(&selfManagedCerts, "self-managed-certs", getEnvBool("SELF_MANAGED_CERTS", false), "Generate a CA and serving certificate, store them in a Secret and inject the caBundle into the webhook configuration")
+	flag.StringVar(&certSecretName, "cert-secret-name", getEnv("CERT_SECRET_NAME", "fencemaster-tls"), "Secret storing the self-managed CA and serving certificate")
+	flag.StringVar(&serviceName, "service-name", getEnv("SERVICE_NAME", "fencemaster"), "Service name the self-managed certificate is issued for")
+	flag.StringVar(&podNamespace, "namespace", getEnv("POD_NAMESPACE", "fencemaster"), "Namespace of the Service and certificate Secret")
+	flag.StringVar(&webhookConfigName, "webhook-config-name", getEnv("WEBHOOK_CONFIG_NAME", ""), "MutatingWebhookConfiguration (and ValidatingWebhookConfiguration of the same name) to inject the self-managed caBundle into")
+	flag.StringVar(&clientCAFile, "client-ca-file", getEnv("CLIENT_CA_FILE", ""), "CA bundle for verifying downstream client certificates (certificate CN must match the cluster name)")
+	flag.StringVar(&clientTokensFile, "client-tokens-file", getEnv("CLIENT_TOKENS_FILE", ""), "CSV file of 'token,cluster-name' lines for per-cluster bearer token authentication")
+	flag.IntVar(&reconcileMins, "reconcile-interval", getEnvInt("RECONCILE_INTERVAL_MINUTES", 0), "Minutes between reconciliations of the namespaces that exist in downstream clusters (0 disables the reconciler)")
+	flag.StringVar(&reconcileMode, "reconcile-mode", getEnv("RECONCILE_MODE", string(reconciler.ModeReport)), "What the reconciler does with namespaces that need changes: report (log and count them) or patch")
+	flag.StringVar(&reconcileClusters, "reconcile-clusters", getEnv("RECONCILE_CLUSTERS", ""), "Comma-separated cluster names or IDs to reconcile (default: all)")
+	flag.StringVar(&reconcileUser, "reconcile-user", getEnv("RECONCILE_USER", reconciler.DefaultUser), "Username the reconciler assigns namespaces as, for the project access policy")
+	flag.StringVar(&reconcileLease, "reconcile-lease", getEnv("RECONCILE_LEASE", "fencemaster-reconciler"), "Lease in --namespace electing the one replica that reconciles")
+	flag.StringVar(&rancherURL, "rancher-url", getEnv("RANCHER_URL", ""), "Rancher server URL to reach downstream clusters through its cluster proxy (default: the kubeconfig Secrets of provisioned clusters)")
+	flag.StringVar(&rancherTokenFile, "rancher-token-file", getEnv("RANCHER_TOKEN_FILE", ""), "File with the Rancher API token for --rancher-url")
+	flag.StringVar(&rancherCAFile, "rancher-ca-file", getEnv("RANCHER_CA_FILE", ""), "CA bundle for verifying the Rancher server certificate (default: system roots)")
+
+	// "fencemaster backfill [flags]" assigns the existing namespaces once instead of serving
+	var backfillOpts backfillOptions
+	backfill := len(os.Args) > 1 && os.Args[1] == backfillCommand
+	if backfill {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		backfillOpts.register(flag.CommandLine)
+	}
 	flag.Parse()
 
+	tlsEnabled := tlsCertFile != "" || tlsKeyFile != "" || selfManagedCerts
+
 	// Parse excluded namespaces
 	var excludedNamespaces []string
 	if excludeNamespaces != "" {
@@ -64,8 +146,28 @@ func main() {
 		}
 	}
 
+	var protected []string
+	for _, project := range strings.Split(protectedProjects, ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			protected = append(protected, project)
+		}
+	}
+
+	var onlyClusters []string
+	for _, cluster := range strings.Split(reconcileClusters, ",") {
+		if cluster = strings.TrimSpace(cluster); cluster != "" {
+			onlyClusters = append(onlyClusters, cluster)
+		}
+	}
+
 	cacheTTL := time.Duration(cacheTTLMins) * time.Minute
+	reconcileInterval := time.Duration(reconcileMins) * time.Minute
+	negativeCacheTTL := time.Duration(negativeTTLSecs) * time.Second
 	logger := logging.Setup(logLevel, logFormat)
+	if backfill {
+		// Backfill results go to stdout
+		logger = logging.SetupWriter(os.Stderr, logLevel, logFormat)
+	}
 
 	logger.Info("Starting fencemaster",
 		slog.String("version", version),
@@ -74,15 +176,185 @@ func main() {
 		slog.Bool("strict_mode", strictMode),
 		slog.Bool("dry_run", dryRun),
 		slog.Duration("cache_ttl", cacheTTL),
+		slog.Duration("negative_cache_ttl", negativeCacheTTL),
+		slog.Bool("informers", useInformers),
+		slog.String("fleet_workspaces", fleetWorkspaces),
+		slog.String("cluster_source", clusterSource),
+		slog.String("cluster_sources", clusterSources),
 		slog.Int("metrics_port", metricsPort),
 		slog.String("project_label", projectLabel),
 		slog.String("project_annotation", projectAnnotation),
 		slog.Any("excluded_namespaces", excludedNamespaces),
+		slog.String("conflict_policy", conflictPolicy),
+		slog.String("label_removal_policy", labelRemoval),
+		slog.String("default_project", defaultProject),
+		slog.String("cluster_default_projects", clusterDefaults),
+		slog.String("project_sources", projectSources),
+		slog.String("tracking_projects_file", trackingFile),
+		slog.String("project_rules_file", projectRulesFile),
+		slog.String("project_access_file", projectAccessFile),
+		slog.Any("protected_projects", protected),
+		slog.Bool("create_projects", createProjects),
+		slog.String("project_template_file", projectTemplate),
+		slog.Bool("tls_enabled", tlsEnabled),
+		slog.Bool("self_managed_certs", selfManagedCerts),
+		slog.Bool("client_cert_auth", clientCAFile != ""),
+		slog.Bool("client_token_auth", clientTokensFile != ""),
+		slog.Duration("reconcile_interval", reconcileInterval),
+		slog.String("reconcile_mode", reconcileMode),
+		slog.Any("reconcile_clusters", onlyClusters),
+		slog.String("rancher_url", rancherURL),
 	)
 
-	config, err := rest.InClusterConfig()
+	if selfManagedCerts && (tlsCertFile != "" || tlsKeyFile != "") {
+		logger.Error("--self-managed-certs cannot be combined with --tls-cert-file/--tls-key-file")
+		os.Exit(1)
+	}
+	if !selfManagedCerts && tlsEnabled && (tlsCertFile == "" || tlsKeyFile == "") {
+		logger.Error("Both --tls-cert-file and --tls-key-file must be set to enable TLS")
+		os.Exit(1)
+	}
+	if err := validateClientAuth(tlsEnabled, clientCAFile, clientTokensFile); err != nil {
+		logger.Error("Invalid client authentication", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	annotationConflictPolicy, err := webhook.ParseConflictPolicy(conflictPolicy)
+	if err != nil {
+		logger.Error("Invalid --conflict-policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	labelRemovalPolicy, err := webhook.ParseLabelRemovalPolicy(labelRemoval)
+	if err != nil {
+		logger.Error("Invalid --label-removal-policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	clusterDefaultProjects, err := webhook.ParseClusterDefaultProjects(clusterDefaults)
+	if err != nil {
+		logger.Error("Invalid --cluster-default-projects", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if labelRemovalPolicy == webhook.LabelRemovalDefault && defaultProject == "" && len(clusterDefaultProjects) == 0 {
+		logger.Error("--label-removal-policy=default requires --default-project or --cluster-default-projects")
+		os.Exit(1)
+	}
+
+	sources, err := webhook.ParseProjectSources(projectSources)
+	if err != nil {
+		logger.Error("Invalid --project-sources", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	var trackingProjects map[string]map[string]string
+	if trackingFile != "" {
+		trackingProjects, err = webhook.LoadTrackingProjects(trackingFile)
+		if err != nil {
+			logger.Error("Failed to load tracking projects", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+	for _, source := range sources {
+		if source.Kind == webhook.ProjectSourceTracking && len(trackingProjects[source.Key]) == 0 {
+			logger.Warn("Tracking project source has no lookup table and will never match",
+				slog.String("source", source.String()),
+			)
+		}
+	}
+
+	var projectRules []webhook.ProjectRule
+	if projectRulesFile != "" {
+		projectRules, err = webhook.LoadProjectRules(projectRulesFile)
+		if err != nil {
+			logger.Error("Failed to load project rules", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logger.Info("Loaded project rules", slog.Int("rules", len(projectRules)))
+	}
+
+	var projectAccess *webhook.ProjectAccessPolicy
+	if projectAccessFile != "" {
+		projectAccess, err = webhook.LoadProjectAccessPolicy(projectAccessFile)
+		if err != nil {
+			logger.Error("Failed to load project access policy", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		logger.Info("Loaded project access policy",
+			slog.String("action", string(projectAccess.Action)),
+			slog.Bool("check_membership", projectAccess.CheckMembership),
+			slog.Int("rules", len(projectAccess.Rules)),
+		)
+	}
+
+	var template rancher.ProjectTemplate
+	if projectTemplate != "" {
+		if !createProjects {
+			logger.Warn("--project-template-file has no effect without --create-projects")
+		}
+		template, err = rancher.LoadProjectTemplate(projectTemplate)
+		if err != nil {
+			logger.Error("Failed to load project template", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	mode, err := reconciler.ParseMode(reconcileMode)
+	if err != nil {
+		logger.Error("Invalid --reconcile-mode", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if mode == reconciler.ModePatch && dryRun && reconcileInterval > 0 {
+		logger.Warn("Reconciler only reports namespaces that need changes in dry-run mode")
+		mode = reconciler.ModeReport
+	}
+	if rancherURL != "" && rancherTokenFile == "" {
+		logger.Error("--rancher-url requires --rancher-token-file")
+		os.Exit(1)
+	}
+	if backfill {
+		onlyClusters, err = backfillOpts.validate()
+		if err != nil {
+			logger.Error("Invalid backfill options", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		mode = backfillMode(dryRun)
+	}
+
+	defaultClusterSource, err := rancher.ParseClusterSource(clusterSource)
+	if err != nil {
+		logger.Error("Invalid --cluster-source", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	clusterSourceOverrides, err := rancher.ParseClusterSources(clusterSources)
+	if err != nil {
+		logger.Error("Invalid --cluster-sources", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Per-cluster authentication is enabled when any client credential source is configured
+	var authenticator webhook.Authenticator
+	if clientCAFile != "" || clientTokensFile != "" {
+		a, err := auth.NewAuthenticator(clientTokensFile)
+		if err != nil {
+			logger.Error("Failed to set up client authentication", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		authenticator = a
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	var config *rest.Config
+	if backfill {
+		config, err = backfillOpts.restConfig()
+	} else {
+		config, err = rest.InClusterConfig()
+	}
 	if err != nil {
-		logger.Error("Failed to get in-cluster config", slog.String("error", err.Error()))
+		logger.Error("Failed to get cluster config", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
@@ -95,18 +367,95 @@ func main() {
 		os.Exit(1)
 	}
 
-	rancherClient := rancher.NewClient(dynamicClient, logger, cacheTTL)
-	handler := webhook.NewHandler(rancherClient, logger, webhook.HandlerConfig{
-		StrictMode:         strictMode,
-		DryRun:             dryRun,
-		ProjectLabel:       projectLabel,
-		ProjectAnnotation:  projectAnnotation,
-		ExcludedNamespaces: excludedNamespaces,
+	rancherClient := rancher.NewClient(dynamicClient, logger, rancher.ClientConfig{
+		CacheTTL:         cacheTTL,
+		NegativeCacheTTL: negativeCacheTTL,
+		FleetWorkspaces:  strings.Split(fleetWorkspaces, ","),
+		ClusterSource:    defaultClusterSource,
+		ClusterSources:   clusterSourceOverrides,
+		ProjectTemplate:  template,
 	})
+	// A backfill reads every project once, so informers would only slow its start
+	if useInformers && !backfill {
+		if err := rancherClient.StartInformers(ctx); err != nil {
+			logger.Error("Failed to start informers", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	} else if !backfill {
+		// Without informers, watches still evict cached lookups as soon as clusters and projects change
+		rancherClient.StartWatches(ctx)
+	}
+	handlerConfig := webhook.HandlerConfig{
+		StrictMode:             strictMode,
+		DryRun:                 dryRun,
+		ProjectLabel:           projectLabel,
+		ProjectAnnotation:      projectAnnotation,
+		ExcludedNamespaces:     excludedNamespaces,
+		ProjectSources:         sources,
+		TrackingProjects:       trackingProjects,
+		Authenticator:          authenticator,
+		ConflictPolicy:         annotationConflictPolicy,
+		LabelRemovalPolicy:     labelRemovalPolicy,
+		DefaultProject:         defaultProject,
+		ClusterDefaultProjects: clusterDefaultProjects,
+		ProjectRules:           projectRules,
+		ProjectAccess:          projectAccess,
+		ProtectedProjects:      protected,
+		CreateProjects:         createProjects,
+	}
+	handler := webhook.NewHandler(rancherClient, logger, handlerConfig)
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logger.Error("Failed to create kubernetes client", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if reconcileInterval > 0 || backfill {
+		var connector reconciler.Connector = reconciler.NewSecretConnector(clientset)
+		if rancherURL != "" {
+			connector = reconciler.NewProxyConnector(rancherURL, rancherTokenFile, rancherCAFile)
+		}
+		if backfill {
+			c, err := backfillOpts.connector()
+			if err != nil {
+				logger.Error("Failed to connect to cluster", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			if c != nil {
+				connector = c
+			}
+		}
+
+		// The reconciler decides whether to patch, so its reviews always compute
+		// the patch; in report mode they never create projects either
+		reviewConfig := handlerConfig
+		reviewConfig.DryRun = false
+		reconcileLogger := logger.With(slog.String("component", "reconciler"))
+		rec := reconciler.New(webhook.NewHandler(rancherClient, reconcileLogger, reviewConfig), rancherClient, connector, reconcileLogger, reconciler.Config{
+			Mode:              mode,
+			Clusters:          onlyClusters,
+			ProjectAnnotation: projectAnnotation,
+			User:              authenticationv1.UserInfo{Username: reconcileUser},
+		})
+
+		if backfill {
+			code := runBackfill(ctx, rec, dryRun, os.Stdout, logger)
+			stop()
+			os.Exit(code)
+		}
+
+		identity := os.Getenv("POD_NAME")
+		if identity == "" {
+			identity, _ = os.Hostname()
+		}
+		go rec.StartLeader(ctx, clientset, podNamespace, reconcileLease, identity, reconcileInterval)
+	}
 
 	// Main webhook server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/mutate/", handler.HandleMutate)
+	mux.HandleFunc("/validate/", handler.HandleValidate)
 
 	// Liveness probe - basic server health
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -134,6 +483,49 @@ func main() {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
+	switch {
+	case selfManagedCerts:
+		certManager := certs.NewManager(clientset, logger, certs.ManagerConfig{
+			Namespace:         podNamespace,
+			SecretName:        certSecretName,
+			ServiceName:       serviceName,
+			WebhookConfigName: webhookConfigName,
+		})
+		if err := certManager.Sync(ctx); err != nil {
+			logger.Error("Failed to set up self-managed certificates", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		go certManager.Start(ctx, certs.DefaultSyncInterval)
+
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certManager.GetCertificate,
+		}
+	case tlsEnabled:
+		reloader, err := certs.NewReloader(tlsCertFile, tlsKeyFile, logger)
+		if err != nil {
+			logger.Error("Failed to load TLS certificate", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		go reloader.Start(ctx, certs.DefaultReloadInterval)
+
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	if clientCAFile != "" {
+		clientCAs, err := auth.LoadClientCAs(clientCAFile)
+		if err != nil {
+			logger.Error("Failed to load client CA", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		// Probes don't present a certificate; the handler enforces credentials on /mutate
+		server.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		server.TLSConfig.ClientCAs = clientCAs
+	}
+
 	// Metrics server on separate port
 	metricsMux := http.NewServeMux()
 	metricsMux.Handle("/metrics", promhttp.Handler())
@@ -160,8 +552,16 @@ func main() {
 		logger.Info("Webhook server started",
 			slog.Int("port", port),
 			slog.String("endpoint", "/mutate"),
+			slog.Bool("tls", tlsEnabled),
 		)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsEnabled {
+			// Certificate is served via TLSConfig.GetCertificate
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("Failed to start server", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
@@ -172,14 +572,15 @@ func main() {
 	sig := <-sigCh
 
 	logger.Info("Received shutdown signal", slog.String("signal", sig.String()))
+	stop()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Error shutting down webhook server", slog.String("error", err.Error()))
 	}
-	if err := metricsServer.Shutdown(ctx); err != nil {
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Error shutting down metrics server", slog.String("error", err.Error()))
 	}
 
@@ -208,3 +609,18 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// validateClientAuth checks that client credentials are only accepted over
+// TLS, since bearer tokens would otherwise travel in cleartext
+func validateClientAuth(tlsEnabled bool, clientCAFile, clientTokensFile string) error {
+	if tlsEnabled {
+		return nil
+	}
+	if clientCAFile != "" {
+		return fmt.Errorf("--client-ca-file requires TLS to be enabled")
+	}
+	if clientTokensFile != "" {
+		return fmt.Errorf("--client-tokens-file requires TLS to be enabled")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestValidateClientAuth(t *testing.T) {
+	tests := []struct {
+		name             string
+		tlsEnabled       bool
+		clientCAFile     string
+		clientTokensFile string
+		expectErr        bool
+	}{
+		{name: "no client authentication", tlsEnabled: false},
+		{name: "client certificates over TLS", tlsEnabled: true, clientCAFile: "/etc/fencemaster/client-ca/ca.crt"},
+		{name: "tokens over TLS", tlsEnabled: true, clientTokensFile: "/etc/fencemaster/tokens/tokens.csv"},
+		{name: "client certificates without TLS", clientCAFile: "/etc/fencemaster/client-ca/ca.crt", expectErr: true},
+		{name: "tokens without TLS", clientTokensFile: "/etc/fencemaster/tokens/tokens.csv", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClientAuth(tt.tlsEnabled, tt.clientCAFile, tt.clientTokensFile)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error=%v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
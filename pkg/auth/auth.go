@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries neither a client certificate nor a bearer token
+	ErrNoCredentials = errors.New("no client credentials provided")
+	// ErrInvalidToken is returned when a bearer token is not known
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+// Authenticator identifies the downstream cluster that sent a request, using
+// either a verified mTLS client certificate or a per-cluster bearer token.
+type Authenticator struct {
+	// tokens maps the SHA-256 of a bearer token to the cluster name it belongs to
+	tokens map[[sha256.Size]byte]string
+}
+
+// NewAuthenticator creates an authenticator from a token file. tokensFile may
+// be empty when only client certificates are used.
+func NewAuthenticator(tokensFile string) (*Authenticator, error) {
+	a := &Authenticator{tokens: make(map[[sha256.Size]byte]string)}
+	if tokensFile == "" {
+		return a, nil
+	}
+
+	f, err := os.Open(tokensFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tokens file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := a.loadTokens(f); err != nil {
+		return nil, fmt.Errorf("failed to load tokens file %s: %w", tokensFile, err)
+	}
+
+	return a, nil
+}
+
+// loadTokens parses CSV lines of the form "token,cluster-name". Lines starting
+// with # are ignored.
+func (a *Authenticator) loadTokens(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		token, cluster := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if token == "" || cluster == "" {
+			return fmt.Errorf("line %d: token and cluster name must not be empty", i+1)
+		}
+		key := sha256.Sum256([]byte(token))
+		if _, exists := a.tokens[key]; exists {
+			return fmt.Errorf("line %d: duplicate token", i+1)
+		}
+		a.tokens[key] = cluster
+	}
+
+	return nil
+}
+
+// Authenticate returns the cluster name the request's credentials belong to.
+// A verified client certificate takes precedence over a bearer token; its
+// Common Name is the cluster name.
+func (a *Authenticator) Authenticate(r *http.Request) (string, error) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		return clusterFromCertificate(r.TLS.VerifiedChains[0][0])
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		return "", ErrNoCredentials
+	}
+
+	cluster, ok := a.tokens[sha256.Sum256([]byte(token))]
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	return cluster, nil
+}
+
+// clusterFromCertificate extracts the cluster name from a client certificate
+func clusterFromCertificate(cert *x509.Certificate) (string, error) {
+	if cert.Subject.CommonName == "" {
+		return "", errors.New("client certificate has no common name")
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// LoadClientCAs reads a PEM bundle of CAs that sign downstream client certificates
+func LoadClientCAs(caFile string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", caFile)
+	}
+
+	return pool, nil
+}
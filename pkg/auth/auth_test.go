@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectError bool
+		expected    int
+	}{
+		{
+			name:     "valid tokens",
+			content:  "token-a,cluster-a\ntoken-b, cluster-b\n",
+			expected: 2,
+		},
+		{
+			name:     "comments are ignored",
+			content:  "# token,cluster\ntoken-a,cluster-a\n",
+			expected: 1,
+		},
+		{
+			name:        "missing cluster",
+			content:     "token-a\n",
+			expectError: true,
+		},
+		{
+			name:        "empty cluster",
+			content:     "token-a,\n",
+			expectError: true,
+		},
+		{
+			name:        "duplicate token",
+			content:     "token-a,cluster-a\ntoken-a,cluster-b\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Authenticator{tokens: make(map[[32]byte]string)}
+			err := a.loadTokens(strings.NewReader(tt.content))
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(a.tokens) != tt.expected {
+				t.Errorf("expected %d tokens, got %d", tt.expected, len(a.tokens))
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator_TokensFile(t *testing.T) {
+	tokensFile := filepath.Join(t.TempDir(), "tokens.csv")
+	if err := os.WriteFile(tokensFile, []byte("secret-a,cluster-a\n"), 0o600); err != nil {
+		t.Fatalf("failed to write tokens file: %v", err)
+	}
+
+	a, err := NewAuthenticator(tokensFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mutate/cluster-a", nil)
+	req.Header.Set("Authorization", "Bearer secret-a")
+
+	cluster, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cluster != "cluster-a" {
+		t.Errorf("expected cluster 'cluster-a', got '%s'", cluster)
+	}
+}
+
+func TestNewAuthenticator_MissingFile(t *testing.T) {
+	_, err := NewAuthenticator(filepath.Join(t.TempDir(), "missing.csv"))
+	if err == nil {
+		t.Error("expected error for missing tokens file")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := &Authenticator{tokens: make(map[[32]byte]string)}
+	if err := a.loadTokens(strings.NewReader("secret-a,cluster-a\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clientCert := &x509.Certificate{Subject: pkix.Name{CommonName: "cluster-b"}}
+
+	tests := []struct {
+		name            string
+		header          string
+		tlsState        *tls.ConnectionState
+		expectedCluster string
+		expectedErr     error
+	}{
+		{
+			name:            "valid bearer token",
+			header:          "Bearer secret-a",
+			expectedCluster: "cluster-a",
+		},
+		{
+			name:            "case-insensitive scheme",
+			header:          "bearer secret-a",
+			expectedCluster: "cluster-a",
+		},
+		{
+			name:        "unknown token",
+			header:      "Bearer secret-x",
+			expectedErr: ErrInvalidToken,
+		},
+		{
+			name:        "basic auth is not accepted",
+			header:      "Basic c2VjcmV0LWE=",
+			expectedErr: ErrNoCredentials,
+		},
+		{
+			name:        "no credentials",
+			expectedErr: ErrNoCredentials,
+		},
+		{
+			name:            "verified client certificate",
+			tlsState:        &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{clientCert}}},
+			expectedCluster: "cluster-b",
+		},
+		{
+			name:            "client certificate takes precedence over token",
+			header:          "Bearer secret-a",
+			tlsState:        &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{clientCert}}},
+			expectedCluster: "cluster-b",
+		},
+		{
+			name:        "unverified TLS connection falls back to token",
+			tlsState:    &tls.ConnectionState{},
+			expectedErr: ErrNoCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mutate/cluster-a", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			req.TLS = tt.tlsState
+
+			cluster, err := a.Authenticate(req)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cluster != tt.expectedCluster {
+				t.Errorf("expected cluster '%s', got '%s'", tt.expectedCluster, cluster)
+			}
+		})
+	}
+}
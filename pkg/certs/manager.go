@@ -0,0 +1,397 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultCAValidity is the lifetime of the generated CA
+	DefaultCAValidity = 10 * 365 * 24 * time.Hour
+	// DefaultCertValidity is the lifetime of the generated serving certificate
+	DefaultCertValidity = 365 * 24 * time.Hour
+	// DefaultRenewBefore is how long before expiry certificates are rotated
+	DefaultRenewBefore = 30 * 24 * time.Hour
+	// DefaultSyncInterval is how often the Secret and webhook configuration are reconciled
+	DefaultSyncInterval = time.Minute
+
+	// Secret data keys
+	caCertKey     = "ca.crt"
+	caKeyKey      = "ca.key"
+	caBundleKey   = "ca-bundle.crt"
+	servingCrtKey = corev1.TLSCertKey
+	servingKeyKey = corev1.TLSPrivateKeyKey
+)
+
+// ManagerConfig contains configuration options for the certificate manager
+type ManagerConfig struct {
+	// Namespace and SecretName locate the Secret that stores the CA and serving certificate
+	Namespace  string
+	SecretName string
+	// ServiceName is the Service fronting the webhook, used to derive the certificate DNS names
+	ServiceName string
+	// WebhookConfigName is the MutatingWebhookConfiguration (and ValidatingWebhookConfiguration
+	// of the same name, if any) whose caBundle is kept in sync
+	WebhookConfigName string
+
+	CAValidity   time.Duration
+	CertValidity time.Duration
+	RenewBefore  time.Duration
+}
+
+// Manager generates a CA and serving certificate, persists them in a Secret
+// shared by all replicas, and injects the CA into the webhook configuration
+type Manager struct {
+	client   kubernetes.Interface
+	logger   *slog.Logger
+	cfg      ManagerConfig
+	dnsNames []string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// certState is the decoded content of the certificate Secret
+type certState struct {
+	ca       *keyPair
+	serving  *keyPair
+	caBundle []byte
+}
+
+func NewManager(client kubernetes.Interface, logger *slog.Logger, cfg ManagerConfig) *Manager {
+	if cfg.CAValidity == 0 {
+		cfg.CAValidity = DefaultCAValidity
+	}
+	if cfg.CertValidity == 0 {
+		cfg.CertValidity = DefaultCertValidity
+	}
+	if cfg.RenewBefore == 0 {
+		cfg.RenewBefore = DefaultRenewBefore
+	}
+
+	return &Manager{
+		client: client,
+		logger: logger,
+		cfg:    cfg,
+		dnsNames: []string{
+			cfg.ServiceName,
+			fmt.Sprintf("%s.%s", cfg.ServiceName, cfg.Namespace),
+			fmt.Sprintf("%s.%s.svc", cfg.ServiceName, cfg.Namespace),
+			fmt.Sprintf("%s.%s.svc.cluster.local", cfg.ServiceName, cfg.Namespace),
+		},
+	}
+}
+
+// GetCertificate returns the current serving certificate. It is meant to be
+// used as tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.cert == nil {
+		return nil, fmt.Errorf("serving certificate not yet available")
+	}
+	return m.cert, nil
+}
+
+// Start reconciles the certificates every interval until ctx is canceled.
+// Sync must have succeeded once before the server starts.
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil {
+				m.logger.Error("Failed to sync webhook certificates",
+					slog.String("secret", m.cfg.SecretName),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// Sync loads the certificates from the Secret, rotates them if they are
+// missing or about to expire, and updates the webhook caBundle
+func (m *Manager) Sync(ctx context.Context) error {
+	secrets := m.client.CoreV1().Secrets(m.cfg.Namespace)
+
+	secret, err := secrets.Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get secret %s: %w", m.cfg.SecretName, err)
+	}
+	if errors.IsNotFound(err) {
+		secret = nil
+	}
+
+	var state *certState
+	if secret != nil {
+		state = m.decode(secret)
+	}
+
+	if m.needsRotation(state) {
+		state, err = m.issue(state)
+		if err != nil {
+			return err
+		}
+
+		if secret == nil {
+			_, err = secrets.Create(ctx, m.encode(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      m.cfg.SecretName,
+					Namespace: m.cfg.Namespace,
+				},
+				Type: corev1.SecretTypeTLS,
+			}, state), metav1.CreateOptions{})
+		} else {
+			_, err = secrets.Update(ctx, m.encode(secret.DeepCopy(), state), metav1.UpdateOptions{})
+		}
+
+		// Another replica rotated the certificates first; use what it stored
+		if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+			m.logger.Debug("Certificate secret changed concurrently, reloading",
+				slog.String("secret", m.cfg.SecretName),
+			)
+			secret, err = secrets.Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get secret %s: %w", m.cfg.SecretName, err)
+			}
+			if state = m.decode(secret); state == nil {
+				return fmt.Errorf("secret %s does not contain valid certificates", m.cfg.SecretName)
+			}
+		} else if err != nil {
+			return fmt.Errorf("failed to store certificates in secret %s: %w", m.cfg.SecretName, err)
+		} else {
+			m.logger.Info("Webhook certificates issued",
+				slog.String("secret", m.cfg.SecretName),
+				slog.Time("ca_not_after", state.ca.cert.NotAfter),
+				slog.Time("cert_not_after", state.serving.cert.NotAfter),
+			)
+		}
+	}
+
+	if err := m.setCertificate(state.serving); err != nil {
+		return err
+	}
+
+	return m.injectCABundle(ctx, state.caBundle)
+}
+
+// decode parses the Secret, returning nil if it does not hold a usable CA
+func (m *Manager) decode(secret *corev1.Secret) *certState {
+	ca, err := parseKeyPair(secret.Data[caCertKey], secret.Data[caKeyKey])
+	if err != nil {
+		return nil
+	}
+
+	state := &certState{ca: ca, caBundle: secret.Data[caBundleKey]}
+	if len(state.caBundle) == 0 {
+		state.caBundle = ca.certPEM
+	}
+
+	// A missing or broken serving certificate is reissued from the existing CA
+	if serving, err := parseKeyPair(secret.Data[servingCrtKey], secret.Data[servingKeyKey]); err == nil {
+		state.serving = serving
+	}
+
+	return state
+}
+
+// encode writes state into secret and returns it
+func (m *Manager) encode(secret *corev1.Secret, state *certState) *corev1.Secret {
+	secret.Data = map[string][]byte{
+		caCertKey:     state.ca.certPEM,
+		caKeyKey:      state.ca.keyPEM,
+		caBundleKey:   state.caBundle,
+		servingCrtKey: state.serving.certPEM,
+		servingKeyKey: state.serving.keyPEM,
+	}
+	return secret
+}
+
+// needsRotation reports whether a new serving certificate must be issued
+func (m *Manager) needsRotation(state *certState) bool {
+	if state == nil || state.serving == nil {
+		return true
+	}
+
+	renewAt := time.Now().Add(m.cfg.RenewBefore)
+	if state.serving.cert.NotAfter.Before(renewAt) || state.ca.cert.NotAfter.Before(renewAt) {
+		return true
+	}
+
+	// The Service may have been renamed since the certificate was issued
+	for _, name := range m.dnsNames {
+		if !slices.Contains(state.serving.cert.DNSNames, name) {
+			return true
+		}
+	}
+
+	if err := state.serving.cert.CheckSignatureFrom(state.ca.cert); err != nil {
+		return true
+	}
+
+	return false
+}
+
+// issue creates a new serving certificate, reusing the CA unless it is about
+// to expire. When the CA is replaced the old one stays in the bundle so that
+// replicas still serving the previous certificate keep working.
+func (m *Manager) issue(previous *certState) (*certState, error) {
+	state := &certState{}
+
+	if previous != nil && previous.ca.cert.NotAfter.After(time.Now().Add(m.cfg.RenewBefore)) {
+		state.ca = previous.ca
+		state.caBundle = previous.ca.certPEM
+	} else {
+		ca, err := generateCA(fmt.Sprintf("%s-ca", m.cfg.ServiceName), m.cfg.CAValidity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate CA: %w", err)
+		}
+		state.ca = ca
+		state.caBundle = ca.certPEM
+		if previous != nil && previous.ca.cert.NotAfter.After(time.Now()) {
+			state.caBundle = append(bytes.Clone(ca.certPEM), previous.ca.certPEM...)
+		}
+	}
+
+	serving, err := generateServingCert(state.ca, m.dnsNames, min(m.cfg.CertValidity, time.Until(state.ca.cert.NotAfter)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serving certificate: %w", err)
+	}
+	state.serving = serving
+
+	return state, nil
+}
+
+// setCertificate swaps the in-memory serving certificate if it changed
+func (m *Manager) setCertificate(serving *keyPair) error {
+	m.mu.RLock()
+	unchanged := m.cert != nil && bytes.Equal(m.cert.Certificate[0], serving.cert.Raw)
+	m.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := serving.tlsCertificate()
+	if err != nil {
+		return fmt.Errorf("failed to load serving certificate: %w", err)
+	}
+
+	m.mu.Lock()
+	m.cert = cert
+	m.mu.Unlock()
+
+	metrics.CertificateReloadsTotal.WithLabelValues(metrics.ResultSuccess).Inc()
+	m.logger.Info("Serving certificate loaded",
+		slog.String("secret", m.cfg.SecretName),
+		slog.Time("not_after", serving.cert.NotAfter),
+	)
+
+	return nil
+}
+
+// injectCABundle sets the caBundle of every webhook in the configured
+// MutatingWebhookConfiguration and ValidatingWebhookConfiguration. A missing
+// configuration is not an error since in server-only installs the webhooks
+// live in downstream clusters, and the validating webhook is optional.
+func (m *Manager) injectCABundle(ctx context.Context, caBundle []byte) error {
+	if m.cfg.WebhookConfigName == "" {
+		return nil
+	}
+
+	if err := m.injectMutatingCABundle(ctx, caBundle); err != nil {
+		return err
+	}
+	return m.injectValidatingCABundle(ctx, caBundle)
+}
+
+// injectMutatingCABundle sets the caBundle of the MutatingWebhookConfiguration
+func (m *Manager) injectMutatingCABundle(ctx context.Context, caBundle []byte) error {
+	webhooks := m.client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	return injectWebhookCABundle(ctx, m, "mutating", webhooks, func(config *admissionregistrationv1.MutatingWebhookConfiguration) []*admissionregistrationv1.WebhookClientConfig {
+		clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, len(config.Webhooks))
+		for i := range config.Webhooks {
+			clientConfigs[i] = &config.Webhooks[i].ClientConfig
+		}
+		return clientConfigs
+	}, caBundle)
+}
+
+// injectValidatingCABundle sets the caBundle of the ValidatingWebhookConfiguration
+func (m *Manager) injectValidatingCABundle(ctx context.Context, caBundle []byte) error {
+	webhooks := m.client.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	return injectWebhookCABundle(ctx, m, "validating", webhooks, func(config *admissionregistrationv1.ValidatingWebhookConfiguration) []*admissionregistrationv1.WebhookClientConfig {
+		clientConfigs := make([]*admissionregistrationv1.WebhookClientConfig, len(config.Webhooks))
+		for i := range config.Webhooks {
+			clientConfigs[i] = &config.Webhooks[i].ClientConfig
+		}
+		return clientConfigs
+	}, caBundle)
+}
+
+// webhookConfigClient gets and updates mutating or validating webhook configurations
+type webhookConfigClient[T any] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Update(ctx context.Context, config T, opts metav1.UpdateOptions) (T, error)
+}
+
+// injectWebhookCABundle sets the caBundle of the client configs of the
+// configured webhook configuration of the given kind, updating it only when
+// one of them changed
+func injectWebhookCABundle[T any](ctx context.Context, m *Manager, kind string, webhooks webhookConfigClient[T], clientConfigs func(T) []*admissionregistrationv1.WebhookClientConfig, caBundle []byte) error {
+	config, err := webhooks.Get(ctx, m.cfg.WebhookConfigName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		m.logger.Debug("Webhook configuration not found, skipping caBundle injection",
+			slog.String("webhook_config", m.cfg.WebhookConfigName),
+			slog.String("kind", kind),
+		)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s webhook configuration %s: %w", kind, m.cfg.WebhookConfigName, err)
+	}
+
+	if !setCABundle(clientConfigs(config), caBundle) {
+		return nil
+	}
+
+	if _, err := webhooks.Update(ctx, config, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update caBundle of %s webhook configuration %s: %w", kind, m.cfg.WebhookConfigName, err)
+	}
+
+	m.logger.Info("Injected caBundle into webhook configuration",
+		slog.String("webhook_config", m.cfg.WebhookConfigName),
+		slog.String("kind", kind),
+	)
+
+	return nil
+}
+
+// setCABundle sets caBundle in every client config, and reports whether any changed
+func setCABundle(clientConfigs []*admissionregistrationv1.WebhookClientConfig, caBundle []byte) bool {
+	changed := false
+	for _, clientConfig := range clientConfigs {
+		if !bytes.Equal(clientConfig.CABundle, caBundle) {
+			clientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,198 @@
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testManagerConfig() ManagerConfig {
+	return ManagerConfig{
+		Namespace:         "fencemaster",
+		SecretName:        "fencemaster-tls",
+		ServiceName:       "fencemaster",
+		WebhookConfigName: "fencemaster",
+	}
+}
+
+func newTestWebhookConfig() *admissionregistrationv1.MutatingWebhookConfiguration {
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "fencemaster"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{
+			{Name: "fencemaster.fencemaster.svc"},
+		},
+	}
+}
+
+func TestManagerSync_CreatesSecretAndInjectsCABundle(t *testing.T) {
+	client := fake.NewSimpleClientset(newTestWebhookConfig())
+	manager := NewManager(client, newTestLogger(), testManagerConfig())
+
+	if err := manager.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secret, err := client.CoreV1().Secrets("fencemaster").Get(context.Background(), "fencemaster-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected secret to be created: %v", err)
+	}
+	for _, key := range []string{caCertKey, caKeyKey, caBundleKey, servingCrtKey, servingKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			t.Errorf("expected secret key %q to be set", key)
+		}
+	}
+
+	config, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "fencemaster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	caBundle := config.Webhooks[0].ClientConfig.CABundle
+	if len(caBundle) == 0 {
+		t.Fatal("expected caBundle to be injected")
+	}
+
+	// The serving certificate must verify against the injected bundle for the service DNS name
+	cert, err := manager.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caBundle)
+	if _, err := cert.Leaf.Verify(x509.VerifyOptions{
+		DNSName: "fencemaster.fencemaster.svc",
+		Roots:   pool,
+	}); err != nil {
+		t.Errorf("serving certificate does not verify against caBundle: %v", err)
+	}
+}
+
+func TestManagerSync_ReusesExistingSecret(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	first := NewManager(client, newTestLogger(), testManagerConfig())
+	if err := first.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A second replica must serve the same certificate instead of issuing a new one
+	second := NewManager(client, newTestLogger(), testManagerConfig())
+	if err := second.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	firstCert, _ := first.GetCertificate(nil)
+	secondCert, _ := second.GetCertificate(nil)
+	if !firstCert.Leaf.Equal(secondCert.Leaf) {
+		t.Error("expected replicas to share the same serving certificate")
+	}
+}
+
+func TestManagerSync_MissingWebhookConfig(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	manager := NewManager(client, newTestLogger(), testManagerConfig())
+
+	if err := manager.Sync(context.Background()); err != nil {
+		t.Fatalf("expected missing webhook configuration to be ignored, got: %v", err)
+	}
+}
+
+func TestNeedsRotation(t *testing.T) {
+	manager := NewManager(fake.NewSimpleClientset(), newTestLogger(), testManagerConfig())
+
+	ca, err := generateCA("test-ca", DefaultCAValidity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	otherCA, err := generateCA("other-ca", DefaultCAValidity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	valid, _ := generateServingCert(ca, manager.dnsNames, DefaultCertValidity)
+	expiring, _ := generateServingCert(ca, manager.dnsNames, 24*time.Hour)
+	wrongNames, _ := generateServingCert(ca, []string{"other.example.com"}, DefaultCertValidity)
+	wrongCA, _ := generateServingCert(otherCA, manager.dnsNames, DefaultCertValidity)
+
+	tests := []struct {
+		name     string
+		state    *certState
+		expected bool
+	}{
+		{"no secret", nil, true},
+		{"missing serving certificate", &certState{ca: ca}, true},
+		{"valid", &certState{ca: ca, serving: valid}, false},
+		{"expiring", &certState{ca: ca, serving: expiring}, true},
+		{"wrong DNS names", &certState{ca: ca, serving: wrongNames}, true},
+		{"signed by another CA", &certState{ca: ca, serving: wrongCA}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manager.needsRotation(tt.state); got != tt.expected {
+				t.Errorf("needsRotation() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIssue_RotatesExpiringCA(t *testing.T) {
+	manager := NewManager(fake.NewSimpleClientset(), newTestLogger(), testManagerConfig())
+
+	oldCA, err := generateCA("old-ca", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, err := manager.issue(&certState{ca: oldCA, caBundle: oldCA.certPEM})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if state.ca.cert.Equal(oldCA.cert) {
+		t.Error("expected expiring CA to be replaced")
+	}
+
+	// The old CA stays trusted until every replica has picked up the new certificate
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(state.caBundle)
+	if _, err := oldCA.cert.Verify(x509.VerifyOptions{Roots: pool}); err != nil {
+		t.Errorf("expected old CA to remain in bundle: %v", err)
+	}
+}
+
+func TestManagerSync_InjectsCABundleIntoValidatingWebhook(t *testing.T) {
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "fencemaster"},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "validate.fencemaster.fencemaster.svc"},
+		},
+	}
+	client := fake.NewSimpleClientset(newTestWebhookConfig(), validating)
+	manager := NewManager(client, newTestLogger(), testManagerConfig())
+
+	if err := manager.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mutating, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "fencemaster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	validating, err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.Background(), "fencemaster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	caBundle := validating.Webhooks[0].ClientConfig.CABundle
+	if len(caBundle) == 0 {
+		t.Fatal("expected caBundle to be injected into the validating webhook")
+	}
+	if string(caBundle) != string(mutating.Webhooks[0].ClientConfig.CABundle) {
+		t.Error("expected mutating and validating webhooks to share the caBundle")
+	}
+}
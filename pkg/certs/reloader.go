@@ -0,0 +1,132 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+)
+
+const (
+	// DefaultReloadInterval is how often the certificate files are checked for changes
+	DefaultReloadInterval = 10 * time.Second
+)
+
+// Reloader serves a TLS certificate loaded from disk and reloads it when the
+// files change (e.g., when cert-manager rotates the mounted Secret)
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// NewReloader loads the certificate and key from disk. It fails if the initial
+// load fails so the server never starts without a valid certificate.
+func NewReloader(certFile, keyFile string, logger *slog.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It is meant to be used as
+// tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Start polls the certificate files until ctx is canceled and swaps in the new
+// certificate whenever their content changes
+func (r *Reloader) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.reload()
+			if err != nil {
+				// Keep serving the previous certificate; the files may be mid-rotation
+				metrics.CertificateReloadsTotal.WithLabelValues(metrics.ResultError).Inc()
+				r.logger.Error("Failed to reload TLS certificate",
+					slog.String("cert_file", r.certFile),
+					slog.String("key_file", r.keyFile),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			if changed {
+				metrics.CertificateReloadsTotal.WithLabelValues(metrics.ResultSuccess).Inc()
+				r.logger.Info("TLS certificate reloaded",
+					slog.String("cert_file", r.certFile),
+					slog.Time("not_after", r.notAfter()),
+				)
+			}
+		}
+	}
+}
+
+// reload reads the certificate files and swaps the certificate if they changed.
+// It returns true if a new certificate was loaded.
+func (r *Reloader) reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read certificate file %s: %w", r.certFile, err)
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read key file %s: %w", r.keyFile, err)
+	}
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certPEM = certPEM
+	r.keyPEM = keyPEM
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// notAfter returns the expiry of the current leaf certificate
+func (r *Reloader) notAfter() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil || r.cert.Leaf == nil {
+		return time.Time{}
+	}
+	return r.cert.Leaf.NotAfter
+}
@@ -0,0 +1,142 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// writeTestCertificate writes a self-signed certificate and key for commonName to dir
+func writeTestCertificate(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{commonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "tls.crt")
+	keyFile = filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestNewReloader_Success(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), "fencemaster.test")
+
+	reloader, err := NewReloader(certFile, keyFile, newTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cert.Leaf == nil || cert.Leaf.Subject.CommonName != "fencemaster.test" {
+		t.Errorf("expected certificate for 'fencemaster.test', got %+v", cert.Leaf)
+	}
+}
+
+func TestNewReloader_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), newTestLogger())
+	if err == nil {
+		t.Error("expected error for missing certificate files")
+	}
+}
+
+func TestReload_PicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "old.test")
+
+	reloader, err := NewReloader(certFile, keyFile, newTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unchanged files should not trigger a reload
+	changed, err := reloader.reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed {
+		t.Error("expected no reload when files are unchanged")
+	}
+
+	writeTestCertificate(t, dir, "new.test")
+
+	changed, err = reloader.reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Error("expected reload after certificate rotation")
+	}
+
+	cert, _ := reloader.GetCertificate(nil)
+	if cert.Leaf.Subject.CommonName != "new.test" {
+		t.Errorf("expected rotated certificate 'new.test', got '%s'", cert.Leaf.Subject.CommonName)
+	}
+}
+
+func TestReload_KeepsPreviousCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "old.test")
+
+	reloader, err := NewReloader(certFile, keyFile, newTestLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate a half-written rotation
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+
+	if _, err := reloader.reload(); err == nil {
+		t.Error("expected error for invalid certificate")
+	}
+
+	cert, _ := reloader.GetCertificate(nil)
+	if cert.Leaf.Subject.CommonName != "old.test" {
+		t.Errorf("expected previous certificate 'old.test' to be kept, got '%s'", cert.Leaf.Subject.CommonName)
+	}
+}
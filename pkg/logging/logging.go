@@ -1,12 +1,18 @@
 package logging
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"strings"
 )
 
 func Setup(level string, format string) *slog.Logger {
+	return SetupWriter(os.Stdout, level, format)
+}
+
+// SetupWriter is Setup writing logs to w, e.g. stderr for commands whose output is on stdout
+func SetupWriter(w io.Writer, level string, format string) *slog.Logger {
 	var logLevel slog.Level
 	switch strings.ToLower(level) {
 	case "debug":
@@ -28,11 +34,11 @@ func Setup(level string, format string) *slog.Logger {
 	var handler slog.Handler
 	switch strings.ToLower(format) {
 	case "json":
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(w, opts)
 	case "text":
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(w, opts)
 	default:
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(w, opts)
 	}
 
 	logger := slog.New(handler)
@@ -43,6 +43,42 @@ var (
 		[]string{"cache_type"},
 	)
 
+	// NegativeCacheHitsTotal counts lookups answered by a cached not-found result
+	NegativeCacheHitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_negative_cache_hits_total",
+			Help: "Total number of negative cache hits",
+		},
+		[]string{"cache_type"},
+	)
+
+	// NegativeCacheMissesTotal counts cache misses with no cached not-found result
+	NegativeCacheMissesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_negative_cache_misses_total",
+			Help: "Total number of negative cache misses",
+		},
+		[]string{"cache_type"},
+	)
+
+	// CoalescedRequestsTotal counts cache misses that joined an in-flight lookup instead of calling the API
+	CoalescedRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_coalesced_requests_total",
+			Help: "Total number of cache misses served by an in-flight lookup for the same key",
+		},
+		[]string{"cache_type"},
+	)
+
+	// CacheInvalidationsTotal counts cache entries invalidated by watch events
+	CacheInvalidationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_cache_invalidations_total",
+			Help: "Total number of cache invalidations caused by renamed or deleted clusters and projects",
+		},
+		[]string{"cache_type", "reason"},
+	)
+
 	// ProjectLookupErrorsTotal counts project lookup errors
 	ProjectLookupErrorsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -60,6 +96,87 @@ var (
 		},
 		[]string{"error_type"},
 	)
+
+	// CertificateReloadsTotal counts TLS certificate reloads from disk
+	CertificateReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_certificate_reloads_total",
+			Help: "Total number of TLS certificate reloads",
+		},
+		[]string{"result"},
+	)
+
+	// ProjectSourcesTotal counts namespaces by where their project was read from
+	ProjectSourcesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_project_sources_total",
+			Help: "Total number of project assignments by the source the project was read from",
+		},
+		[]string{"source"},
+	)
+
+	// ProjectConflictsTotal counts namespaces whose project annotation disagrees with their project label
+	ProjectConflictsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_project_conflicts_total",
+			Help: "Total number of project annotations that conflict with the project label, by conflict policy",
+		},
+		[]string{"policy"},
+	)
+
+	// ValidationDenialsTotal counts namespaces rejected by the validating webhook
+	ValidationDenialsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_validation_denials_total",
+			Help: "Total number of namespaces rejected by the validating webhook",
+		},
+		[]string{"reason"},
+	)
+
+	// ProjectAccessDenialsTotal counts project assignments the requesting user was not allowed to make
+	ProjectAccessDenialsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_project_access_denials_total",
+			Help: "Total number of project assignments refused by the project access policy, by action",
+		},
+		[]string{"action"},
+	)
+
+	// ProjectsCreatedTotal counts projects created for namespaces labeled with a missing project
+	ProjectsCreatedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_projects_created_total",
+			Help: "Total number of projects created for namespaces whose project didn't exist, by result",
+		},
+		[]string{"result"},
+	)
+
+	// ReconciledNamespacesTotal counts namespaces the reconciler reviewed, by result
+	ReconciledNamespacesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_reconciled_namespaces_total",
+			Help: "Total number of existing namespaces reviewed by the reconciler, by result",
+		},
+		[]string{"result"},
+	)
+
+	// ReconcileRunsTotal counts reconciliations of a downstream cluster
+	ReconcileRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_reconcile_runs_total",
+			Help: "Total number of downstream cluster reconciliations, by result",
+		},
+		[]string{"result"},
+	)
+
+	// AuthFailuresTotal counts requests rejected by per-cluster authentication
+	AuthFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fencemaster_auth_failures_total",
+			Help: "Total number of requests rejected by cluster authentication",
+		},
+		[]string{"reason"},
+	)
 )
 
 // Status constants for request metrics
@@ -74,12 +191,50 @@ const (
 
 // CacheType constants
 const (
-	CacheTypeCluster = "cluster"
-	CacheTypeProject = "project"
+	CacheTypeCluster         = "cluster"
+	CacheTypeProject         = "project"
+	CacheTypeProjectMembers  = "project_members"
+	CacheTypeProjectDefaults = "project_defaults"
+)
+
+// InvalidationReason constants
+const (
+	InvalidationReasonUpdated = "updated"
+	InvalidationReasonDeleted = "deleted"
 )
 
 // ErrorType constants
 const (
-	ErrorTypeNotFound = "not_found"
-	ErrorTypeAPI      = "api_error"
+	ErrorTypeNotFound  = "not_found"
+	ErrorTypeAPI       = "api_error"
+	ErrorTypeAmbiguous = "ambiguous"
+)
+
+// ProjectSource constants for projects not read from a configured source
+// (configured sources are recorded as kind:key, e.g. "label:project")
+const (
+	ProjectSourceRule    = "rule"
+	ProjectSourceDefault = "default"
+)
+
+// ValidationReason constants
+const (
+	ValidationReasonInvalid          = "invalid_annotation"
+	ValidationReasonClusterMismatch  = "cluster_mismatch"
+	ValidationReasonProjectNotFound  = "project_not_found"
+	ValidationReasonLabelMismatch    = "label_mismatch"
+	ValidationReasonAnnotationEdited = "annotation_edited"
+	ValidationReasonProjectRefused   = "project_refused"
+)
+
+// AuthFailure constants
+const (
+	AuthFailureUnauthenticated = "unauthenticated"
+	AuthFailureClusterMismatch = "cluster_mismatch"
+)
+
+// Result constants
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
 )
@@ -83,6 +83,75 @@ func TestCacheMissesTotal(t *testing.T) {
 	}
 }
 
+func TestNegativeCacheTotals(t *testing.T) {
+	// Reset the counters for testing
+	NegativeCacheHitsTotal.Reset()
+	NegativeCacheMissesTotal.Reset()
+
+	// Increment negative cache hits and misses
+	NegativeCacheHitsTotal.WithLabelValues(CacheTypeCluster).Inc()
+	NegativeCacheHitsTotal.WithLabelValues(CacheTypeCluster).Inc()
+	NegativeCacheMissesTotal.WithLabelValues(CacheTypeProject).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(NegativeCacheHitsTotal.WithLabelValues(CacheTypeCluster)); got != 2 {
+		t.Errorf("expected cluster negative cache hits of 2, got %f", got)
+	}
+	if got := testutil.ToFloat64(NegativeCacheMissesTotal.WithLabelValues(CacheTypeProject)); got != 1 {
+		t.Errorf("expected project negative cache misses of 1, got %f", got)
+	}
+}
+
+func TestProjectSourcesTotal(t *testing.T) {
+	// Reset the counter for testing
+	ProjectSourcesTotal.Reset()
+
+	// Increment project sources
+	ProjectSourcesTotal.WithLabelValues("label:project").Inc()
+	ProjectSourcesTotal.WithLabelValues(ProjectSourceRule).Inc()
+	ProjectSourcesTotal.WithLabelValues(ProjectSourceRule).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(ProjectSourcesTotal.WithLabelValues("label:project")); got != 1 {
+		t.Errorf("expected label:project source count of 1, got %f", got)
+	}
+	if got := testutil.ToFloat64(ProjectSourcesTotal.WithLabelValues(ProjectSourceRule)); got != 2 {
+		t.Errorf("expected rule source count of 2, got %f", got)
+	}
+}
+
+func TestCoalescedRequestsTotal(t *testing.T) {
+	// Reset the counter for testing
+	CoalescedRequestsTotal.Reset()
+
+	// Increment coalesced requests
+	CoalescedRequestsTotal.WithLabelValues(CacheTypeProject).Inc()
+	CoalescedRequestsTotal.WithLabelValues(CacheTypeProject).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(CoalescedRequestsTotal.WithLabelValues(CacheTypeProject)); got != 2 {
+		t.Errorf("expected project coalesced requests of 2, got %f", got)
+	}
+}
+
+func TestCacheInvalidationsTotal(t *testing.T) {
+	// Reset the counter for testing
+	CacheInvalidationsTotal.Reset()
+
+	// Increment invalidations
+	CacheInvalidationsTotal.WithLabelValues(CacheTypeProject, InvalidationReasonUpdated).Inc()
+	CacheInvalidationsTotal.WithLabelValues(CacheTypeProject, InvalidationReasonDeleted).Inc()
+	CacheInvalidationsTotal.WithLabelValues(CacheTypeCluster, InvalidationReasonDeleted).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(CacheInvalidationsTotal.WithLabelValues(CacheTypeProject, InvalidationReasonUpdated)); got != 1 {
+		t.Errorf("expected project/updated invalidations of 1, got %f", got)
+	}
+	if got := testutil.ToFloat64(CacheInvalidationsTotal.WithLabelValues(CacheTypeCluster, InvalidationReasonDeleted)); got != 1 {
+		t.Errorf("expected cluster/deleted invalidations of 1, got %f", got)
+	}
+}
+
 func TestProjectLookupErrorsTotal(t *testing.T) {
 	// Reset the counter for testing
 	ProjectLookupErrorsTotal.Reset()
@@ -119,6 +188,42 @@ func TestClusterLookupErrorsTotal(t *testing.T) {
 	}
 }
 
+func TestCertificateReloadsTotal(t *testing.T) {
+	// Reset the counter for testing
+	CertificateReloadsTotal.Reset()
+
+	// Increment reloads
+	CertificateReloadsTotal.WithLabelValues(ResultSuccess).Inc()
+	CertificateReloadsTotal.WithLabelValues(ResultError).Inc()
+	CertificateReloadsTotal.WithLabelValues(ResultError).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(CertificateReloadsTotal.WithLabelValues(ResultSuccess)); got != 1 {
+		t.Errorf("expected success reloads of 1, got %f", got)
+	}
+	if got := testutil.ToFloat64(CertificateReloadsTotal.WithLabelValues(ResultError)); got != 2 {
+		t.Errorf("expected error reloads of 2, got %f", got)
+	}
+}
+
+func TestAuthFailuresTotal(t *testing.T) {
+	// Reset the counter for testing
+	AuthFailuresTotal.Reset()
+
+	// Increment failures
+	AuthFailuresTotal.WithLabelValues(AuthFailureUnauthenticated).Inc()
+	AuthFailuresTotal.WithLabelValues(AuthFailureClusterMismatch).Inc()
+	AuthFailuresTotal.WithLabelValues(AuthFailureClusterMismatch).Inc()
+
+	// Verify counts
+	if got := testutil.ToFloat64(AuthFailuresTotal.WithLabelValues(AuthFailureUnauthenticated)); got != 1 {
+		t.Errorf("expected unauthenticated failures of 1, got %f", got)
+	}
+	if got := testutil.ToFloat64(AuthFailuresTotal.WithLabelValues(AuthFailureClusterMismatch)); got != 2 {
+		t.Errorf("expected cluster_mismatch failures of 2, got %f", got)
+	}
+}
+
 func TestMetricsAreRegistered(t *testing.T) {
 	// Verify that all metrics are registered with the default registry
 	metrics := []prometheus.Collector{
@@ -126,8 +231,21 @@ func TestMetricsAreRegistered(t *testing.T) {
 		RequestDuration,
 		CacheHitsTotal,
 		CacheMissesTotal,
+		NegativeCacheHitsTotal,
+		NegativeCacheMissesTotal,
+		CoalescedRequestsTotal,
+		CacheInvalidationsTotal,
 		ProjectLookupErrorsTotal,
 		ClusterLookupErrorsTotal,
+		CertificateReloadsTotal,
+		ProjectSourcesTotal,
+		ProjectConflictsTotal,
+		ValidationDenialsTotal,
+		ProjectAccessDenialsTotal,
+		ProjectsCreatedTotal,
+		ReconciledNamespacesTotal,
+		ReconcileRunsTotal,
+		AuthFailuresTotal,
 	}
 
 	for _, m := range metrics {
@@ -172,4 +290,7 @@ func TestErrorTypeConstants(t *testing.T) {
 	if ErrorTypeAPI != "api_error" {
 		t.Errorf("expected ErrorTypeAPI to be 'api_error', got '%s'", ErrorTypeAPI)
 	}
+	if ErrorTypeAmbiguous != "ambiguous" {
+		t.Errorf("expected ErrorTypeAmbiguous to be 'ambiguous', got '%s'", ErrorTypeAmbiguous)
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/rvbsalgado/fencemaster/pkg/metrics"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -41,10 +42,39 @@ type cacheEntry struct {
 	expiresAt time.Time
 }
 
+// ClientConfig contains configuration options for the Rancher client
+type ClientConfig struct {
+	// CacheTTL is how long successful lookups are cached
+	CacheTTL time.Duration
+	// NegativeCacheTTL is how long not-found lookups are cached (0 disables negative caching)
+	NegativeCacheTTL time.Duration
+	// FleetWorkspaces are the namespaces provisioning clusters are looked up in.
+	// Defaults to fleet-default; "*" looks clusters up in all namespaces.
+	FleetWorkspaces []string
+	// ClusterSource is the resource cluster names are resolved from. Defaults
+	// to provisioning clusters only.
+	ClusterSource ClusterSource
+	// ClusterSources overrides ClusterSource for individual cluster names
+	ClusterSources map[string]ClusterSource
+	// ProjectTemplate is the spec of projects created by CreateProject
+	ProjectTemplate ProjectTemplate
+}
+
 type Client struct {
-	dynamicClient dynamic.Interface
-	logger        *slog.Logger
-	cacheTTL      time.Duration
+	dynamicClient    dynamic.Interface
+	logger           *slog.Logger
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
+	workspaces       workspaces
+
+	// defaultClusterSource applies to cluster names without an entry in clusterSources
+	defaultClusterSource ClusterSource
+	clusterSources       map[string]ClusterSource
+
+	projectTemplate ProjectTemplate
+
+	// index serves lookups from informers once synced; nil when informers are disabled
+	index *Index
 
 	clusterCache map[string]cacheEntry
 	clusterMu    sync.RWMutex
@@ -52,15 +82,54 @@ type Client struct {
 	// projectCache key is "clusterID:projectDisplayName"
 	projectCache map[string]cacheEntry
 	projectMu    sync.RWMutex
+
+	// negativeCache holds not-found results so misconfigured namespaces can't
+	// amplify API load. Key is "cluster:clusterName" or
+	// "project:clusterID:projectDisplayName"; value is the error message.
+	negativeCache map[string]cacheEntry
+	negativeMu    sync.RWMutex
+
+	// defaultsCache holds project namespace defaults, keyed by "clusterID:projectID"
+	defaultsCache map[string]namespaceDefaults
+	defaultsMu    sync.RWMutex
+
+	// members caches the members of every project for IsProjectMember
+	members   *memberSnapshot
+	membersMu sync.RWMutex
+
+	// In-flight API lookups, so concurrent misses for the same key share one call
+	clusterFlights  *flightGroup[string]
+	projectFlights  *flightGroup[string]
+	defaultsFlights *flightGroup[namespaceDefaults]
+	memberFlights   *flightGroup[*memberSnapshot]
 }
 
-func NewClient(dynamicClient dynamic.Interface, logger *slog.Logger, cacheTTL time.Duration) *Client {
+func NewClient(dynamicClient dynamic.Interface, logger *slog.Logger, cfg ClientConfig) *Client {
+	if cfg.ClusterSource == "" {
+		cfg.ClusterSource = ClusterSourceProvisioning
+	}
+
 	c := &Client{
-		dynamicClient: dynamicClient,
-		logger:        logger,
-		cacheTTL:      cacheTTL,
-		clusterCache:  make(map[string]cacheEntry),
-		projectCache:  make(map[string]cacheEntry),
+		dynamicClient:    dynamicClient,
+		logger:           logger,
+		cacheTTL:         cfg.CacheTTL,
+		negativeCacheTTL: cfg.NegativeCacheTTL,
+		workspaces:       newWorkspaces(cfg.FleetWorkspaces),
+
+		defaultClusterSource: cfg.ClusterSource,
+		clusterSources:       cfg.ClusterSources,
+
+		projectTemplate: cfg.ProjectTemplate,
+
+		clusterCache:     make(map[string]cacheEntry),
+		projectCache:     make(map[string]cacheEntry),
+		negativeCache:    make(map[string]cacheEntry),
+		defaultsCache:    make(map[string]namespaceDefaults),
+
+		clusterFlights:  newFlightGroup[string](),
+		projectFlights:  newFlightGroup[string](),
+		defaultsFlights: newFlightGroup[namespaceDefaults](),
+		memberFlights:   newFlightGroup[*memberSnapshot](),
 	}
 
 	// Start background goroutine to evict expired cache entries
@@ -69,6 +138,24 @@ func NewClient(dynamicClient dynamic.Interface, logger *slog.Logger, cacheTTL ti
 	return c
 }
 
+// StartInformers watches clusters and projects and serves lookups from the
+// in-memory index once it has synced. Until then, lookups fall back to the
+// API with the TTL cache, whose entries are evicted on rename or delete
+// events. Must be called before the client is used.
+func (c *Client) StartInformers(ctx context.Context) error {
+	c.index = newIndex(c.dynamicClient, c.workspaces, c.usesManagementClusters())
+	if err := c.registerInvalidationHandlers(); err != nil {
+		return fmt.Errorf("failed to register cache invalidation handlers: %w", err)
+	}
+	c.index.Start(ctx)
+	return nil
+}
+
+// indexReady reports whether lookups can be served from the informer index
+func (c *Client) indexReady() bool {
+	return c.index != nil && c.index.HasSynced()
+}
+
 // startCacheEviction periodically removes expired entries from caches
 func (c *Client) startCacheEviction() {
 	ticker := time.NewTicker(c.cacheTTL)
@@ -79,7 +166,7 @@ func (c *Client) startCacheEviction() {
 	}
 }
 
-// evictExpiredEntries removes all expired entries from both caches
+// evictExpiredEntries removes all expired entries from all caches
 func (c *Client) evictExpiredEntries() {
 	now := time.Now()
 
@@ -98,6 +185,55 @@ func (c *Client) evictExpiredEntries() {
 		}
 	}
 	c.projectMu.Unlock()
+
+	c.negativeMu.Lock()
+	for key, entry := range c.negativeCache {
+		if now.After(entry.expiresAt) {
+			delete(c.negativeCache, key)
+		}
+	}
+	c.negativeMu.Unlock()
+
+	c.defaultsMu.Lock()
+	for key, entry := range c.defaultsCache {
+		if now.After(entry.expiresAt) {
+			delete(c.defaultsCache, key)
+		}
+	}
+	c.defaultsMu.Unlock()
+}
+
+// getNegative returns the cached not-found error for key, if any
+func (c *Client) getNegative(key, cacheType string) error {
+	if c.negativeCacheTTL <= 0 {
+		return nil
+	}
+
+	c.negativeMu.RLock()
+	entry, ok := c.negativeCache[key]
+	c.negativeMu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		metrics.NegativeCacheMissesTotal.WithLabelValues(cacheType).Inc()
+		return nil
+	}
+
+	metrics.NegativeCacheHitsTotal.WithLabelValues(cacheType).Inc()
+	return notFound(fmt.Errorf("%s", entry.value))
+}
+
+// setNegative caches a not-found error for key
+func (c *Client) setNegative(key string, err error) {
+	if c.negativeCacheTTL <= 0 {
+		return
+	}
+
+	c.negativeMu.Lock()
+	c.negativeCache[key] = cacheEntry{
+		value:     err.Error(),
+		expiresAt: time.Now().Add(c.negativeCacheTTL),
+	}
+	c.negativeMu.Unlock()
 }
 
 // isRetryableError returns true if the error is transient and should be retried
@@ -119,6 +255,10 @@ func isRetryableError(err error) bool {
 
 // GetClusterID returns the management cluster ID (e.g., c-m-xxxxx) for a given cluster name
 func (c *Client) GetClusterID(ctx context.Context, clusterName string) (string, error) {
+	if c.indexReady() {
+		return c.getClusterIDFromIndex(clusterName)
+	}
+
 	// Check cache first
 	c.clusterMu.RLock()
 	if entry, ok := c.clusterCache[clusterName]; ok && time.Now().Before(entry.expiresAt) {
@@ -132,23 +272,97 @@ func (c *Client) GetClusterID(ctx context.Context, clusterName string) (string,
 	}
 	c.clusterMu.RUnlock()
 
-	// Cache miss - query API with retries
+	// Cache miss - query API, sharing the call with concurrent misses
 	metrics.CacheMissesTotal.WithLabelValues(metrics.CacheTypeCluster).Inc()
 
+	if err := c.getNegative("cluster:"+clusterName, metrics.CacheTypeCluster); err != nil {
+		c.logger.Debug("Cluster not-found cache hit", slog.String("cluster", clusterName))
+		return "", err
+	}
+
+	clusterID, shared, err := c.clusterFlights.do(ctx, clusterName, func(ctx context.Context) (string, error) {
+		return c.resolveClusterID(ctx, clusterName)
+	})
+	if shared {
+		metrics.CoalescedRequestsTotal.WithLabelValues(metrics.CacheTypeCluster).Inc()
+	}
+	return clusterID, err
+}
+
+// resolveClusterID looks a cluster up in the API according to its source and caches the result
+func (c *Client) resolveClusterID(ctx context.Context, clusterName string) (string, error) {
+	var clusterID string
+	var err error
+
+	switch c.clusterSource(clusterName) {
+	case ClusterSourceProvisioning:
+		clusterID, err = c.fetchClusterID(ctx, clusterName)
+	case ClusterSourceManagement:
+		clusterID, err = c.fetchManagementClusterID(ctx, clusterName)
+	default:
+		clusterID, err = c.fetchClusterID(ctx, clusterName)
+		if isNotFound(err) {
+			c.logger.Debug("Provisioning cluster not found, trying management clusters",
+				slog.String("cluster", clusterName),
+				slog.String("error", err.Error()),
+			)
+			clusterID, err = c.fetchManagementClusterID(ctx, clusterName)
+			if isNotFound(err) {
+				err = notFound(fmt.Errorf("cluster %s not found as a provisioning or management cluster", clusterName))
+			}
+		}
+	}
+
+	if isAmbiguous(err) {
+		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAmbiguous).Inc()
+		return "", err
+	}
+	if isNotFound(err) {
+		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
+		c.setNegative("cluster:"+clusterName, err)
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Store in cache
+	c.clusterMu.Lock()
+	c.clusterCache[clusterName] = cacheEntry{
+		value:     clusterID,
+		expiresAt: time.Now().Add(c.cacheTTL),
+	}
+	c.clusterMu.Unlock()
+
+	c.logger.Debug("Cluster ID cached",
+		slog.String("cluster", clusterName),
+		slog.String("cluster_id", clusterID),
+		slog.Duration("ttl", c.cacheTTL),
+	)
+
+	return clusterID, nil
+}
+
+// fetchClusterID reads a provisioning cluster with retries and returns its management cluster ID
+func (c *Client) fetchClusterID(ctx context.Context, clusterName string) (string, error) {
 	var cluster *unstructured.Unstructured
 	var err error
 	backoff := initialBackoff
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		cluster, err = c.dynamicClient.Resource(clusterGVR).Namespace("fleet-default").Get(
-			ctx,
-			clusterName,
-			metav1.GetOptions{},
-		)
+		cluster, err = c.getCluster(ctx, clusterName)
 		if err == nil {
 			break
 		}
 
+		if isAmbiguous(err) {
+			return "", err
+		}
+
+		if errors.IsNotFound(err) {
+			return "", notFound(fmt.Errorf("failed to get cluster %s: %w", clusterName, err))
+		}
+
 		if !isRetryableError(err) || attempt == maxRetries {
 			metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
 			return "", fmt.Errorf("failed to get cluster %s: %w", clusterName, err)
@@ -175,29 +389,62 @@ func (c *Client) GetClusterID(ctx context.Context, clusterName string) (string,
 		return "", fmt.Errorf("failed to get clusterName from status: %w", err)
 	}
 	if !found {
-		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
-		return "", fmt.Errorf("clusterName not found in cluster %s status", clusterName)
+		return "", notFound(fmt.Errorf("clusterName not found in cluster %s status", clusterName))
 	}
 
-	// Store in cache
-	c.clusterMu.Lock()
-	c.clusterCache[clusterName] = cacheEntry{
-		value:     clusterID,
-		expiresAt: time.Now().Add(c.cacheTTL),
+	return clusterID, nil
+}
+
+// getCluster reads a provisioning cluster from the configured Fleet workspaces.
+// It returns a NotFound error when no workspace has a cluster with that name.
+func (c *Client) getCluster(ctx context.Context, clusterName string) (*unstructured.Unstructured, error) {
+	clusters := c.dynamicClient.Resource(clusterGVR)
+
+	// A single workspace is a direct Get, which needs no list permission
+	if namespace := c.workspaces.namespace(); namespace != "" {
+		return clusters.Namespace(namespace).Get(ctx, clusterName, metav1.GetOptions{})
 	}
-	c.clusterMu.Unlock()
 
-	c.logger.Debug("Cluster ID cached",
-		slog.String("cluster", clusterName),
-		slog.String("cluster_id", clusterID),
-		slog.Duration("ttl", c.cacheTTL),
-	)
+	var candidates []*unstructured.Unstructured
+	if c.workspaces.all() {
+		list, err := clusters.List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", clusterName).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			candidates = append(candidates, &list.Items[i])
+		}
+	} else {
+		for _, workspace := range c.workspaces.names {
+			cluster, err := clusters.Namespace(workspace).Get(ctx, clusterName, metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, cluster)
+		}
+	}
 
-	return clusterID, nil
+	cluster, err := c.workspaces.selectCluster(clusterName, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, errors.NewNotFound(clusterGVR.GroupResource(), clusterName)
+	}
+	return cluster, nil
 }
 
 // GetProjectID returns the project ID (e.g., p-xxxxx) for a given project display name in a cluster
 func (c *Client) GetProjectID(ctx context.Context, clusterID, projectDisplayName string) (string, error) {
+	if c.indexReady() {
+		return c.getProjectIDFromIndex(clusterID, projectDisplayName)
+	}
+
 	cacheKey := clusterID + ":" + projectDisplayName
 
 	// Check cache first
@@ -214,9 +461,30 @@ func (c *Client) GetProjectID(ctx context.Context, clusterID, projectDisplayName
 	}
 	c.projectMu.RUnlock()
 
-	// Cache miss - query API with retries
+	// Cache miss - query API, sharing the call with concurrent misses
 	metrics.CacheMissesTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
 
+	if err := c.getNegative("project:"+cacheKey, metrics.CacheTypeProject); err != nil {
+		c.logger.Debug("Project not-found cache hit",
+			slog.String("cluster_id", clusterID),
+			slog.String("project", projectDisplayName),
+		)
+		return "", err
+	}
+
+	projectID, shared, err := c.projectFlights.do(ctx, cacheKey, func(ctx context.Context) (string, error) {
+		return c.fetchProjectID(ctx, clusterID, projectDisplayName)
+	})
+	if shared {
+		metrics.CoalescedRequestsTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+	}
+	return projectID, err
+}
+
+// fetchProjectID lists the cluster's projects with retries and caches the match
+func (c *Client) fetchProjectID(ctx context.Context, clusterID, projectDisplayName string) (string, error) {
+	cacheKey := clusterID + ":" + projectDisplayName
+
 	var projects *unstructured.UnstructuredList
 	var err error
 	backoff := initialBackoff
@@ -280,7 +548,83 @@ func (c *Client) GetProjectID(ctx context.Context, clusterID, projectDisplayName
 	}
 
 	metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
-	return "", fmt.Errorf("project %s not found in cluster %s", projectDisplayName, clusterID)
+	err = notFound(fmt.Errorf("project %s not found in cluster %s", projectDisplayName, clusterID))
+	c.setNegative("project:"+cacheKey, err)
+	return "", err
+}
+
+// getClusterIDFromIndex resolves a cluster name using the informer index
+func (c *Client) getClusterIDFromIndex(clusterName string) (string, error) {
+	var clusterID string
+	var found bool
+	var err error
+
+	source := c.clusterSource(clusterName)
+	if source != ClusterSourceManagement {
+		clusterID, found, err = c.index.clusterID(clusterName)
+	}
+	if source != ClusterSourceProvisioning && err == nil && !found {
+		clusterID, found, err = c.index.managementClusterID(clusterName)
+	}
+
+	if isAmbiguous(err) {
+		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAmbiguous).Inc()
+		return "", err
+	}
+	if err != nil {
+		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+		return "", fmt.Errorf("failed to get cluster %s from index: %w", clusterName, err)
+	}
+	if !found {
+		metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
+		return "", notFound(fmt.Errorf("cluster %s not found or has no clusterName in status", clusterName))
+	}
+
+	c.logger.Debug("Cluster ID index hit",
+		slog.String("cluster", clusterName),
+		slog.String("cluster_id", clusterID),
+	)
+	metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeCluster).Inc()
+
+	return clusterID, nil
+}
+
+// getProjectIDFromIndex resolves a project display name using the informer index
+func (c *Client) getProjectIDFromIndex(clusterID, projectDisplayName string) (string, error) {
+	projectID, found, err := c.index.projectID(clusterID, projectDisplayName)
+	if err != nil {
+		metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+		return "", fmt.Errorf("failed to get project %s from index: %w", projectDisplayName, err)
+	}
+	if !found {
+		// A project created moments ago may not have reached the informers yet
+		if projectID, ok := c.cachedProject(clusterID + ":" + projectDisplayName); ok {
+			return projectID, nil
+		}
+		metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
+		return "", notFound(fmt.Errorf("project %s not found in cluster %s", projectDisplayName, clusterID))
+	}
+
+	c.logger.Debug("Project ID index hit",
+		slog.String("cluster_id", clusterID),
+		slog.String("project", projectDisplayName),
+		slog.String("project_id", projectID),
+	)
+	metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+
+	return projectID, nil
+}
+
+// cachedProject returns an unexpired project cache entry
+func (c *Client) cachedProject(cacheKey string) (string, bool) {
+	c.projectMu.RLock()
+	defer c.projectMu.RUnlock()
+
+	entry, ok := c.projectCache[cacheKey]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.value, true
 }
 
 // ClearCache clears all cached entries
@@ -293,6 +637,18 @@ func (c *Client) ClearCache() {
 	c.projectCache = make(map[string]cacheEntry)
 	c.projectMu.Unlock()
 
+	c.negativeMu.Lock()
+	c.negativeCache = make(map[string]cacheEntry)
+	c.negativeMu.Unlock()
+
+	c.defaultsMu.Lock()
+	c.defaultsCache = make(map[string]namespaceDefaults)
+	c.defaultsMu.Unlock()
+
+	c.membersMu.Lock()
+	c.members = nil
+	c.membersMu.Unlock()
+
 	c.logger.Info("Cache cleared")
 }
 
@@ -311,9 +667,27 @@ func (c *Client) CacheStats() (clusterEntries, projectEntries int) {
 
 // HealthCheck verifies connectivity to the Kubernetes API and access to Rancher CRDs
 func (c *Client) HealthCheck(ctx context.Context) error {
-	// Check if we can list clusters (verifies API connectivity and RBAC for clusters.provisioning.cattle.io)
-	if err := c.healthCheckResource(ctx, clusterGVR, "fleet-default", "clusters.provisioning.cattle.io"); err != nil {
-		return err
+	// Not ready until lookups can be served without hitting the API
+	if c.index != nil && !c.index.HasSynced() {
+		return fmt.Errorf("informer cache not synced")
+	}
+
+	// Check if we can list clusters in every workspace (verifies API connectivity and RBAC for clusters.provisioning.cattle.io)
+	clusterNamespaces := c.workspaces.names
+	if c.workspaces.all() {
+		clusterNamespaces = []string{""}
+	}
+	for _, namespace := range clusterNamespaces {
+		if err := c.healthCheckResource(ctx, clusterGVR, namespace, "clusters.provisioning.cattle.io"); err != nil {
+			return err
+		}
+	}
+
+	// Imported and RKE1 clusters are resolved from management clusters
+	if c.usesManagementClusters() {
+		if err := c.healthCheckResource(ctx, managementClusterGVR, "", "clusters.management.cattle.io"); err != nil {
+			return err
+		}
 	}
 
 	// Check if we can access projects API (verifies RBAC for projects.management.cattle.io)
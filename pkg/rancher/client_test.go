@@ -9,22 +9,32 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 )
 
 func newTestLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
 
+func testClientConfig() ClientConfig {
+	return ClientConfig{
+		CacheTTL:         5 * time.Minute,
+		NegativeCacheTTL: 30 * time.Second,
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	scheme := runtime.NewScheme()
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	if client == nil {
 		t.Fatal("expected non-nil client")
@@ -38,6 +48,12 @@ func TestNewClient(t *testing.T) {
 	if client.projectCache == nil {
 		t.Error("expected projectCache to be initialized")
 	}
+	if client.negativeCacheTTL != 30*time.Second {
+		t.Errorf("expected negativeCacheTTL to be 30s, got %v", client.negativeCacheTTL)
+	}
+	if client.negativeCache == nil {
+		t.Error("expected negativeCache to be initialized")
+	}
 }
 
 func TestGetClusterID_CacheHit(t *testing.T) {
@@ -45,7 +61,7 @@ func TestGetClusterID_CacheHit(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate cache
 	client.clusterCache["test-cluster"] = cacheEntry{
@@ -67,7 +83,7 @@ func TestGetClusterID_CacheExpired(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate cache with expired entry
 	client.clusterCache["test-cluster"] = cacheEntry{
@@ -87,7 +103,7 @@ func TestGetClusterID_NotFound(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	_, err := client.GetClusterID(context.Background(), "non-existent-cluster")
 	if err == nil {
@@ -116,7 +132,7 @@ func TestGetClusterID_Success(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme, cluster)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	clusterID, err := client.GetClusterID(context.Background(), "test-cluster")
 	if err != nil {
@@ -137,7 +153,7 @@ func TestGetProjectID_CacheHit(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate cache
 	client.projectCache["c-m-12345:platform"] = cacheEntry{
@@ -164,7 +180,7 @@ func TestGetProjectID_NotFound(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	_, err := client.GetProjectID(context.Background(), "c-m-12345", "non-existent-project")
 	if err == nil {
@@ -197,7 +213,7 @@ func TestGetProjectID_Success(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind, project)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	projectID, err := client.GetProjectID(context.Background(), "c-m-cluster1", "platform")
 	if err != nil {
@@ -218,7 +234,7 @@ func TestClearCache(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate caches
 	client.clusterCache["cluster1"] = cacheEntry{value: "c-m-1", expiresAt: time.Now().Add(5 * time.Minute)}
@@ -240,7 +256,7 @@ func TestCacheStats(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate caches
 	client.clusterCache["cluster1"] = cacheEntry{value: "c-m-1", expiresAt: time.Now().Add(5 * time.Minute)}
@@ -266,11 +282,12 @@ func TestHealthCheck_Success(t *testing.T) {
 	gvrToListKind := map[schema.GroupVersionResource]string{
 		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
 		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
 	}
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	ctx := context.Background()
 	err := client.HealthCheck(ctx)
@@ -288,11 +305,12 @@ func TestHealthCheck_ContextCanceled(t *testing.T) {
 	gvrToListKind := map[schema.GroupVersionResource]string{
 		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
 		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
 	}
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
@@ -313,11 +331,12 @@ func TestHealthCheck_ChecksBothResources(t *testing.T) {
 	gvrToListKind := map[schema.GroupVersionResource]string{
 		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
 		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
 	}
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	ctx := context.Background()
 	err := client.HealthCheck(ctx)
@@ -333,7 +352,7 @@ func TestCacheConcurrency(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	// Pre-populate cache
 	client.clusterCache["test-cluster"] = cacheEntry{
@@ -424,7 +443,7 @@ func TestGetClusterID_ContextCanceled(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
@@ -445,7 +464,7 @@ func TestGetProjectID_ContextCanceled(t *testing.T) {
 	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
 	logger := newTestLogger()
 
-	client := NewClient(dynamicClient, logger, 5*time.Minute)
+	client := NewClient(dynamicClient, logger, testClientConfig())
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
@@ -455,3 +474,260 @@ func TestGetProjectID_ContextCanceled(t *testing.T) {
 	// This test ensures the code path works correctly
 	_ = err
 }
+
+// newTestIndexedClient returns a client whose lookups are served from a synced informer index
+func newTestIndexedClient(t *testing.T, objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	return newTestIndexedClientWithConfig(t, testClientConfig(), objects...)
+}
+
+func newTestIndexedClientWithConfig(t *testing.T, cfg ClientConfig, objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, objects...)
+
+	client := NewClient(dynamicClient, newTestLogger(), cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := client.StartInformers(ctx); err != nil {
+		t.Fatalf("failed to start informers: %v", err)
+	}
+
+	if !cache.WaitForCacheSync(ctx.Done(), client.index.HasSynced) {
+		t.Fatal("timed out waiting for informer index to sync")
+	}
+
+	return client, dynamicClient
+}
+
+func newTestProject(name, namespace, displayName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Project",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]any{
+				"displayName": displayName,
+			},
+		},
+	}
+}
+
+func TestGetClusterID_FromIndex(t *testing.T) {
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "provisioning.cattle.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "test-cluster",
+				"namespace": "fleet-default",
+			},
+			"status": map[string]any{
+				"clusterName": "c-m-abc123",
+			},
+		},
+	}
+
+	client, _ := newTestIndexedClient(t, cluster)
+
+	clusterID, err := client.GetClusterID(context.Background(), "test-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-m-abc123" {
+		t.Errorf("expected cluster ID 'c-m-abc123', got '%s'", clusterID)
+	}
+
+	// Index lookups don't populate the TTL cache
+	if len(client.clusterCache) != 0 {
+		t.Errorf("expected empty cluster cache, got %d entries", len(client.clusterCache))
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "missing-cluster"); !isNotFound(err) {
+		t.Errorf("expected not-found error for cluster missing from index, got %v", err)
+	}
+}
+
+func TestGetProjectID_FromIndex(t *testing.T) {
+	client, _ := newTestIndexedClient(t,
+		newTestProject("p-abc123", "c-m-cluster1", "platform"),
+		newTestProject("p-def456", "c-m-cluster2", "platform"),
+	)
+
+	projectID, err := client.GetProjectID(context.Background(), "c-m-cluster2", "platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if projectID != "p-def456" {
+		t.Errorf("expected project ID 'p-def456', got '%s'", projectID)
+	}
+
+	if _, err := client.GetProjectID(context.Background(), "c-m-cluster1", "missing"); err == nil {
+		t.Error("expected error for project missing from index")
+	}
+}
+
+func TestGetProjectID_IndexFollowsRename(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	renamed := newTestProject("p-abc123", "c-m-cluster1", "platform-v2")
+	if _, err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Update(context.Background(), renamed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to rename project: %v", err)
+	}
+
+	// Watch events are delivered asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, oldErr := client.GetProjectID(context.Background(), "c-m-cluster1", "platform")
+		newID, newErr := client.GetProjectID(context.Background(), "c-m-cluster1", "platform-v2")
+		if oldErr != nil && newErr == nil && newID == "p-abc123" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("index did not follow rename: old lookup err=%v, new lookup id=%q err=%v", oldErr, newID, newErr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func countActions(client *dynamicfake.FakeDynamicClient, verb, resource string) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == resource {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGetClusterID_NegativeCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, testClientConfig())
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetClusterID(context.Background(), "missing"); err == nil {
+			t.Fatal("expected error for non-existent cluster")
+		}
+	}
+
+	if got := countActions(dynamicClient, "get", "clusters"); got != 1 {
+		t.Errorf("expected 1 API call for repeated not-found lookups, got %d", got)
+	}
+	if _, ok := client.negativeCache["cluster:missing"]; !ok {
+		t.Error("expected not-found cluster to be negatively cached")
+	}
+}
+
+func TestGetClusterID_NegativeCacheExpired(t *testing.T) {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, testClientConfig())
+
+	// Pre-populate an expired not-found entry
+	client.negativeCache["cluster:missing"] = cacheEntry{
+		value:     "cluster missing not found",
+		expiresAt: time.Now().Add(-1 * time.Minute),
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for non-existent cluster")
+	}
+	if got := countActions(dynamicClient, "get", "clusters"); got != 1 {
+		t.Errorf("expected expired negative entry to query the API, got %d calls", got)
+	}
+}
+
+func TestGetClusterID_NegativeCacheDisabled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, ClientConfig{CacheTTL: 5 * time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetClusterID(context.Background(), "missing"); err == nil {
+			t.Fatal("expected error for non-existent cluster")
+		}
+	}
+
+	if got := countActions(dynamicClient, "get", "clusters"); got != 2 {
+		t.Errorf("expected every lookup to query the API, got %d calls", got)
+	}
+	if len(client.negativeCache) != 0 {
+		t.Errorf("expected negative cache to stay empty, got %d entries", len(client.negativeCache))
+	}
+}
+
+func TestGetClusterID_APIErrorNotNegativelyCached(t *testing.T) {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	dynamicClient.PrependReactor("get", "clusters", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(clusterGVR.GroupResource(), "test-cluster", fmt.Errorf("denied"))
+	})
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, testClientConfig())
+
+	if _, err := client.GetClusterID(context.Background(), "test-cluster"); err == nil {
+		t.Fatal("expected error for forbidden cluster lookup")
+	}
+	if len(client.negativeCache) != 0 {
+		t.Errorf("expected API errors not to be negatively cached, got %d entries", len(client.negativeCache))
+	}
+}
+
+func TestGetProjectID_NegativeCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		projectGVR: "ProjectList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, gvrToListKind)
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, testClientConfig())
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetProjectID(context.Background(), "c-m-12345", "missing"); err == nil {
+			t.Fatal("expected error for non-existent project")
+		}
+	}
+
+	if got := countActions(dynamicClient, "list", "projects"); got != 1 {
+		t.Errorf("expected 1 API call for repeated not-found lookups, got %d", got)
+	}
+	if _, ok := client.negativeCache["project:c-m-12345:missing"]; !ok {
+		t.Error("expected not-found project to be negatively cached")
+	}
+}
+
+func TestClearCache_ClearsNegativeCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(scheme)
+	logger := newTestLogger()
+
+	client := NewClient(dynamicClient, logger, testClientConfig())
+	client.negativeCache["cluster:missing"] = cacheEntry{
+		value:     "cluster missing not found",
+		expiresAt: time.Now().Add(time.Minute),
+	}
+
+	client.ClearCache()
+
+	if len(client.negativeCache) != 0 {
+		t.Errorf("expected negative cache to be cleared, got %d entries", len(client.negativeCache))
+	}
+}
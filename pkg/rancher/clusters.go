@@ -0,0 +1,69 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Cluster is a downstream cluster namespaces are assigned to projects in
+type Cluster struct {
+	// Name is the provisioning cluster name, or the display name of a
+	// management cluster without one, as in the webhook URL path
+	Name string
+	// Workspace is the Fleet workspace of a provisioning cluster ("" for management clusters)
+	Workspace string
+	// ID is the management cluster ID (c-xxxxx or c-m-xxxxx)
+	ID string
+}
+
+// ListClusters lists the downstream clusters: provisioning clusters in the
+// configured Fleet workspaces and, when cluster names may be resolved from
+// them, management clusters without a provisioning cluster. Provisioning
+// clusters that haven't been given a management cluster yet are skipped.
+func (c *Client) ListClusters(ctx context.Context) ([]Cluster, error) {
+	var clusters []Cluster
+	seen := make(map[string]struct{})
+
+	list, err := c.dynamicClient.Resource(clusterGVR).Namespace(c.workspaces.namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	for _, cluster := range list.Items {
+		if !c.workspaces.contains(cluster.GetNamespace()) {
+			continue
+		}
+		clusterID, _, _ := unstructured.NestedString(cluster.Object, "status", "clusterName")
+		if clusterID == "" {
+			continue
+		}
+		seen[clusterID] = struct{}{}
+		clusters = append(clusters, Cluster{Name: cluster.GetName(), Workspace: cluster.GetNamespace(), ID: clusterID})
+	}
+
+	if c.usesManagementClusters() {
+		list, err := c.dynamicClient.Resource(managementClusterGVR).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list management clusters: %w", err)
+		}
+		for _, cluster := range list.Items {
+			if _, ok := seen[cluster.GetName()]; ok {
+				continue
+			}
+			name := displayNameOf(&cluster)
+			if name == "" {
+				name = cluster.GetName()
+			}
+			clusters = append(clusters, Cluster{Name: name, ID: cluster.GetName()})
+		}
+	}
+
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return clusters, nil
+}
@@ -0,0 +1,55 @@
+package rancher
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestListClusters(t *testing.T) {
+	cfg := testClientConfig()
+	cfg.FleetWorkspaces = []string{"fleet-default", "fleet-custom"}
+	cfg.ClusterSource = ClusterSourceAuto
+
+	pending := newTestCluster("pending", "fleet-default", "")
+	client, _ := newTestWorkspaceClient(cfg,
+		newTestCluster("prod", "fleet-default", "c-m-abc123"),
+		newTestCluster("dev", "fleet-custom", "c-m-def456"),
+		newTestCluster("other", "fleet-other", "c-m-ghi789"),
+		pending,
+		newTestManagementCluster("c-m-abc123", "prod"),
+		newTestManagementCluster("c-xyz12", "legacy"),
+	)
+
+	clusters, err := client.ListClusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Cluster{
+		{Name: "dev", Workspace: "fleet-custom", ID: "c-m-def456"},
+		{Name: "legacy", ID: "c-xyz12"},
+		{Name: "prod", Workspace: "fleet-default", ID: "c-m-abc123"},
+	}
+	if !slices.Equal(clusters, expected) {
+		t.Errorf("expected clusters %v, got %v", expected, clusters)
+	}
+}
+
+func TestListClusters_ProvisioningOnly(t *testing.T) {
+	client, dynamicClient := newTestWorkspaceClient(testClientConfig(),
+		newTestCluster("prod", "fleet-default", "c-m-abc123"),
+		newTestManagementCluster("c-xyz12", "legacy"),
+	)
+
+	clusters, err := client.ListClusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "prod" {
+		t.Errorf("expected only the provisioning cluster, got %v", clusters)
+	}
+	if got := countActions(dynamicClient, "list", "clusters"); got != 1 {
+		t.Errorf("expected management clusters not to be listed, got %d cluster lists", got)
+	}
+}
@@ -0,0 +1,195 @@
+package rancher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultProjectDescription describes created projects when the template doesn't
+const defaultProjectDescription = "Created by fencemaster"
+
+// createdByLabel marks projects created by fencemaster
+const createdByLabel = "app.kubernetes.io/created-by"
+
+// ProjectTemplate is the spec of the projects CreateProject creates. Quotas
+// and limits use the fields of the Rancher project spec, e.g.
+//
+//	description: Created by fencemaster
+//	resourceQuota:
+//	  limit:
+//	    limitsCpu: 4000m
+//	namespaceDefaultResourceQuota:
+//	  limit:
+//	    limitsCpu: 1000m
+//	containerDefaultResourceLimit:
+//	  limitsCpu: 500m
+//	  limitsMemory: 512Mi
+type ProjectTemplate struct {
+	Description                   string         `json:"description,omitempty"`
+	ResourceQuota                 map[string]any `json:"resourceQuota,omitempty"`
+	NamespaceDefaultResourceQuota map[string]any `json:"namespaceDefaultResourceQuota,omitempty"`
+	ContainerDefaultResourceLimit map[string]any `json:"containerDefaultResourceLimit,omitempty"`
+}
+
+// LoadProjectTemplate reads a project template from a YAML file
+func LoadProjectTemplate(templateFile string) (ProjectTemplate, error) {
+	var template ProjectTemplate
+
+	data, err := os.ReadFile(templateFile)
+	if err != nil {
+		return template, fmt.Errorf("failed to read project template file: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, &template); err != nil {
+		return template, fmt.Errorf("failed to load project template file %s: %w", templateFile, err)
+	}
+	return template, nil
+}
+
+// projectIDFor derives the ID of a created project from its cluster and
+// display name, so that replicas creating the same project at once create
+// one project rather than two with the same display name
+func projectIDFor(clusterID, displayName string) string {
+	sum := sha256.Sum256([]byte(clusterID + ":" + displayName))
+	return "p-" + strings.ToLower(base32.StdEncoding.EncodeToString(sum[:])[:5])
+}
+
+// CreateProject creates a project with the given display name in a cluster
+// from the project template and returns its ID. Concurrent calls for the
+// same project share one creation.
+func (c *Client) CreateProject(ctx context.Context, clusterID, displayName string) (string, error) {
+	cacheKey := clusterID + ":" + displayName
+
+	projectID, _, err := c.projectFlights.do(ctx, "create/"+cacheKey, func(ctx context.Context) (string, error) {
+		return c.createProject(ctx, clusterID, displayName)
+	})
+	if err != nil {
+		metrics.ProjectsCreatedTotal.WithLabelValues(metrics.ResultError).Inc()
+		return "", err
+	}
+
+	// Serve the new project until the informers or the next list see it
+	c.projectMu.Lock()
+	expiresAt := time.Now().Add(c.cacheTTL)
+	c.projectCache[cacheKey] = cacheEntry{value: projectID, expiresAt: expiresAt}
+	c.projectCache[projectIDCacheKey(clusterID, projectID)] = cacheEntry{value: projectID, expiresAt: expiresAt}
+	c.projectMu.Unlock()
+
+	c.negativeMu.Lock()
+	delete(c.negativeCache, "project:"+cacheKey)
+	delete(c.negativeCache, "project:"+projectIDCacheKey(clusterID, projectID))
+	c.negativeMu.Unlock()
+
+	metrics.ProjectsCreatedTotal.WithLabelValues(metrics.ResultSuccess).Inc()
+	return projectID, nil
+}
+
+// createProject creates the project object with retries. A project that
+// already exists under the derived ID is reused when it has the same display
+// name, and otherwise the project is created under a generated ID.
+func (c *Client) createProject(ctx context.Context, clusterID, displayName string) (string, error) {
+	project := c.newProject(clusterID, displayName)
+	project.SetName(projectIDFor(clusterID, displayName))
+
+	var created *unstructured.Unstructured
+	var err error
+	backoff := initialBackoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		created, err = c.dynamicClient.Resource(projectGVR).Namespace(clusterID).Create(ctx, project, metav1.CreateOptions{})
+		if err == nil {
+			break
+		}
+
+		if errors.IsAlreadyExists(err) && project.GetName() != "" {
+			existing, getErr := c.dynamicClient.Resource(projectGVR).Namespace(clusterID).Get(ctx, project.GetName(), metav1.GetOptions{})
+			if getErr != nil {
+				return "", fmt.Errorf("failed to get existing project %s in cluster %s: %w", project.GetName(), clusterID, getErr)
+			}
+			if name, _, _ := unstructured.NestedString(existing.Object, "spec", "displayName"); name == displayName {
+				return existing.GetName(), nil
+			}
+			// The derived ID is taken by another project; let the API server generate one
+			project.SetName("")
+			project.SetGenerateName("p-")
+			continue
+		}
+
+		if !isRetryableError(err) || attempt == maxRetries {
+			return "", fmt.Errorf("failed to create project %s in cluster %s: %w", displayName, clusterID, err)
+		}
+
+		c.logger.Debug("Retrying project creation",
+			slog.String("cluster_id", clusterID),
+			slog.String("project", displayName),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create project %s in cluster %s: %w", displayName, clusterID, err)
+	}
+
+	c.logger.Info("Created project",
+		slog.String("cluster_id", clusterID),
+		slog.String("project", displayName),
+		slog.String("project_id", created.GetName()),
+	)
+	return created.GetName(), nil
+}
+
+// newProject builds a project object from the project template
+func (c *Client) newProject(clusterID, displayName string) *unstructured.Unstructured {
+	description := c.projectTemplate.Description
+	if description == "" {
+		description = defaultProjectDescription
+	}
+
+	spec := map[string]any{
+		"clusterName": clusterID,
+		"displayName": displayName,
+		"description": description,
+	}
+	for field, value := range map[string]map[string]any{
+		"resourceQuota":                 c.projectTemplate.ResourceQuota,
+		"namespaceDefaultResourceQuota": c.projectTemplate.NamespaceDefaultResourceQuota,
+		"containerDefaultResourceLimit": c.projectTemplate.ContainerDefaultResourceLimit,
+	} {
+		if len(value) > 0 {
+			spec[field] = runtime.DeepCopyJSONValue(value)
+		}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": projectGVR.GroupVersion().String(),
+		"kind":       "Project",
+		"metadata": map[string]any{
+			"namespace": clusterID,
+			"labels": map[string]any{
+				createdByLabel: "fencemaster",
+			},
+		},
+		"spec": spec,
+	}}
+}
@@ -0,0 +1,123 @@
+package rancher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLoadProjectTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "template.yaml")
+	data := `
+description: Team project
+resourceQuota:
+  limit:
+    limitsCpu: 4000m
+containerDefaultResourceLimit:
+  limitsMemory: 512Mi
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	template, err := LoadProjectTemplate(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if template.Description != "Team project" || template.ResourceQuota == nil || template.ContainerDefaultResourceLimit == nil {
+		t.Errorf("unexpected template: %+v", template)
+	}
+
+	if err := os.WriteFile(path, []byte("labels: {team: a}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProjectTemplate(path); err == nil {
+		t.Error("expected error for unknown template field")
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	cfg := testClientConfig()
+	cfg.ProjectTemplate = ProjectTemplate{
+		ResourceQuota:                 map[string]any{"limit": map[string]any{"limitsCpu": "4000m"}},
+		ContainerDefaultResourceLimit: map[string]any{"limitsMemory": "512Mi"},
+	}
+	client, dynamicClient := newTestWorkspaceClient(cfg)
+
+	// A missing project is negatively cached by the lookup that found it missing
+	if _, err := client.GetProjectID(context.Background(), "c-m-abc123", "payments"); !isNotFound(err) {
+		t.Fatalf("expected not-found error, got %v", err)
+	}
+
+	projectID, err := client.CreateProject(context.Background(), "c-m-abc123", "payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if projectID != projectIDFor("c-m-abc123", "payments") {
+		t.Errorf("expected derived project ID %s, got %s", projectIDFor("c-m-abc123", "payments"), projectID)
+	}
+
+	project, err := dynamicClient.Resource(projectGVR).Namespace("c-m-abc123").Get(context.Background(), projectID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected project to be created: %v", err)
+	}
+	for field, expected := range map[string]string{
+		"displayName": "payments",
+		"clusterName": "c-m-abc123",
+		"description": defaultProjectDescription,
+	} {
+		if got, _, _ := unstructured.NestedString(project.Object, "spec", field); got != expected {
+			t.Errorf("expected spec.%s=%s, got %q", field, expected, got)
+		}
+	}
+	if got, _, _ := unstructured.NestedString(project.Object, "spec", "resourceQuota", "limit", "limitsCpu"); got != "4000m" {
+		t.Errorf("expected resource quota from template, got %q", got)
+	}
+	if got, _, _ := unstructured.NestedString(project.Object, "spec", "containerDefaultResourceLimit", "limitsMemory"); got != "512Mi" {
+		t.Errorf("expected container limits from template, got %q", got)
+	}
+
+	// The new project is served from the cache, not the negative cache
+	if got, err := client.GetProjectID(context.Background(), "c-m-abc123", "payments"); err != nil || got != projectID {
+		t.Errorf("expected created project %s to be found, got %q (err: %v)", projectID, got, err)
+	}
+	if exists, err := client.ProjectExists(context.Background(), "c-m-abc123", projectID); err != nil || !exists {
+		t.Errorf("expected created project to exist, got %v (err: %v)", exists, err)
+	}
+}
+
+func TestCreateProject_ReusesExistingProject(t *testing.T) {
+	projectID := projectIDFor("c-m-abc123", "payments")
+	client, dynamicClient := newTestWorkspaceClient(testClientConfig(), newTestProject(projectID, "c-m-abc123", "payments"))
+
+	// Another replica created the project first
+	got, err := client.CreateProject(context.Background(), "c-m-abc123", "payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != projectID {
+		t.Errorf("expected existing project %s, got %s", projectID, got)
+	}
+	if n := countActions(dynamicClient, "create", "projects"); n != 1 {
+		t.Errorf("expected 1 create attempt, got %d", n)
+	}
+}
+
+func TestCreateProject_FromIndex(t *testing.T) {
+	client, _ := newTestIndexedClient(t)
+
+	projectID, err := client.CreateProject(context.Background(), "c-m-abc123", "payments")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Served from the cache until the informers see the project
+	if got, err := client.GetProjectID(context.Background(), "c-m-abc123", "payments"); err != nil || got != projectID {
+		t.Errorf("expected created project %s to be found, got %q (err: %v)", projectID, got, err)
+	}
+}
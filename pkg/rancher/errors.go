@@ -0,0 +1,42 @@
+package rancher
+
+import "errors"
+
+// ErrAmbiguousCluster is returned when a cluster name matches more than one cluster
+var ErrAmbiguousCluster = errors.New("ambiguous cluster name")
+
+// isAmbiguous reports whether err is caused by an ambiguous cluster name
+func isAmbiguous(err error) bool {
+	return errors.Is(err, ErrAmbiguousCluster)
+}
+
+// notFoundError is a lookup failure caused by a missing object rather than by
+// the API, so it can be negatively cached or fall back to another source
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string { return e.err.Error() }
+
+func (e *notFoundError) Unwrap() error { return e.err }
+
+// NotFound lets callers outside this package tell not-found lookup failures
+// from API errors without depending on this package
+func (e *notFoundError) NotFound() bool { return true }
+
+// notFound marks err as a not-found lookup failure
+func notFound(err error) error {
+	return &notFoundError{err: err}
+}
+
+// NewNotFoundError marks err as a not-found lookup failure, as the client
+// returns for missing clusters and projects
+func NewNotFoundError(err error) error {
+	return notFound(err)
+}
+
+// isNotFound reports whether err is a not-found lookup failure
+func isNotFound(err error) bool {
+	var nf *notFoundError
+	return errors.As(err, &nf)
+}
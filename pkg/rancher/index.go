@@ -0,0 +1,213 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// clusterNameIndex indexes clusters by name across workspaces
+	clusterNameIndex = "name"
+	// managementClusterDisplayNameIndex indexes management clusters by display name
+	managementClusterDisplayNameIndex = "displayName"
+	// projectDisplayNameIndex indexes projects by "clusterID:displayName"
+	projectDisplayNameIndex = "clusterIDDisplayName"
+)
+
+// Index keeps clusters and projects in memory using informers, so lookups
+// never hit the API server and always reflect the latest watch events
+type Index struct {
+	workspaces workspaces
+	clusters   cache.SharedIndexInformer
+	projects   cache.SharedIndexInformer
+	// managementClusters is nil unless clusters are resolved from clusters.management.cattle.io
+	managementClusters cache.SharedIndexInformer
+}
+
+// newIndex creates informers for provisioning clusters in the given Fleet
+// workspaces and for management projects, plus management clusters when
+// withManagementClusters is set. Call Start to begin watching.
+func newIndex(dynamicClient dynamic.Interface, ws workspaces, withManagementClusters bool) *Index {
+	i := &Index{
+		workspaces: ws,
+		clusters: dynamicinformer.NewFilteredDynamicInformer(
+			dynamicClient, clusterGVR, ws.namespace(), 0, cache.Indexers{
+				clusterNameIndex: indexClusterByName,
+			}, nil,
+		).Informer(),
+		projects: dynamicinformer.NewFilteredDynamicInformer(
+			dynamicClient, projectGVR, "", 0, cache.Indexers{
+				projectDisplayNameIndex: indexProjectByDisplayName,
+			}, nil,
+		).Informer(),
+	}
+
+	if withManagementClusters {
+		i.managementClusters = dynamicinformer.NewFilteredDynamicInformer(
+			dynamicClient, managementClusterGVR, "", 0, cache.Indexers{
+				managementClusterDisplayNameIndex: indexManagementClusterByDisplayName,
+			}, nil,
+		).Informer()
+	}
+
+	return i
+}
+
+// Start runs the informers until ctx is canceled
+func (i *Index) Start(ctx context.Context) {
+	go i.clusters.Run(ctx.Done())
+	go i.projects.Run(ctx.Done())
+	if i.managementClusters != nil {
+		go i.managementClusters.Run(ctx.Done())
+	}
+}
+
+// HasSynced reports whether all informers have completed their initial list
+func (i *Index) HasSynced() bool {
+	if i.managementClusters != nil && !i.managementClusters.HasSynced() {
+		return false
+	}
+	return i.clusters.HasSynced() && i.projects.HasSynced()
+}
+
+// clusterID returns the management cluster ID for a provisioning cluster name
+func (i *Index) clusterID(clusterName string) (string, bool, error) {
+	objs, err := i.clusters.GetIndexer().ByIndex(clusterNameIndex, clusterName)
+	if err != nil || len(objs) == 0 {
+		return "", false, err
+	}
+
+	candidates := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		cluster, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return "", false, fmt.Errorf("unexpected object type %T in cluster index", obj)
+		}
+		candidates = append(candidates, cluster)
+	}
+
+	cluster, err := i.workspaces.selectCluster(clusterName, candidates)
+	if err != nil || cluster == nil {
+		return "", false, err
+	}
+
+	clusterID, found, err := unstructured.NestedString(cluster.Object, "status", "clusterName")
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	return clusterID, true, nil
+}
+
+// managementClusterID returns the ID of a management cluster matched by ID or display name
+func (i *Index) managementClusterID(clusterName string) (string, bool, error) {
+	if i.managementClusters == nil {
+		return "", false, nil
+	}
+
+	_, exists, err := i.managementClusters.GetStore().GetByKey(clusterName)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return clusterName, true, nil
+	}
+
+	objs, err := i.managementClusters.GetIndexer().ByIndex(managementClusterDisplayNameIndex, clusterName)
+	if err != nil || len(objs) == 0 {
+		return "", false, err
+	}
+
+	candidates := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		cluster, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return "", false, fmt.Errorf("unexpected object type %T in management cluster index", obj)
+		}
+		candidates = append(candidates, cluster)
+	}
+
+	clusterID, err := selectManagementCluster(clusterName, candidates)
+	if err != nil {
+		return "", false, err
+	}
+	return clusterID, true, nil
+}
+
+// projectID returns the project ID for a display name in a cluster
+func (i *Index) projectID(clusterID, displayName string) (string, bool, error) {
+	objs, err := i.projects.GetIndexer().ByIndex(projectDisplayNameIndex, clusterID+":"+displayName)
+	if err != nil || len(objs) == 0 {
+		return "", false, err
+	}
+
+	project, ok := objs[0].(*unstructured.Unstructured)
+	if !ok {
+		return "", false, fmt.Errorf("unexpected object type %T in project index", objs[0])
+	}
+
+	return project.GetName(), true, nil
+}
+
+// project returns a project by ID
+func (i *Index) project(clusterID, projectID string) (*unstructured.Unstructured, bool, error) {
+	obj, exists, err := i.projects.GetStore().GetByKey(clusterID + "/" + projectID)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	project, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected object type %T in project index", obj)
+	}
+	return project, true, nil
+}
+
+// projectExists reports whether a project ID exists in a cluster
+func (i *Index) projectExists(clusterID, projectID string) (bool, error) {
+	_, exists, err := i.projects.GetStore().GetByKey(clusterID + "/" + projectID)
+	return exists, err
+}
+
+// indexClusterByName keys a cluster by its name, regardless of workspace
+func indexClusterByName(obj any) ([]string, error) {
+	cluster, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+	return []string{cluster.GetName()}, nil
+}
+
+// indexManagementClusterByDisplayName keys a management cluster by its display name
+func indexManagementClusterByDisplayName(obj any) ([]string, error) {
+	cluster, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+
+	displayName := displayNameOf(cluster)
+	if displayName == "" {
+		return nil, nil
+	}
+	return []string{displayName}, nil
+}
+
+// indexProjectByDisplayName keys a project by its cluster namespace and display name
+func indexProjectByDisplayName(obj any) ([]string, error) {
+	project, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, nil
+	}
+
+	displayName, found, err := unstructured.NestedString(project.Object, "spec", "displayName")
+	if err != nil || !found {
+		return nil, nil
+	}
+
+	return []string{project.GetNamespace() + ":" + displayName}, nil
+}
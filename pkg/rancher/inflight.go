@@ -0,0 +1,57 @@
+package rancher
+
+import (
+	"context"
+	"sync"
+)
+
+// call is a lookup in progress whose result is shared by every caller
+// that asked for the same key while it was running
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// flightGroup deduplicates concurrent lookups for the same key
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+func newFlightGroup[T any]() *flightGroup[T] {
+	return &flightGroup[T]{calls: make(map[string]*call[T])}
+}
+
+// do runs fn once for all concurrent callers with the same key. shared is
+// true when the caller joined a lookup started by another caller.
+//
+// fn runs detached from the caller's cancellation so that one caller giving
+// up doesn't fail the others; each caller still stops waiting when its own
+// ctx is done.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (value T, shared bool, err error) {
+	g.mu.Lock()
+	c, shared := g.calls[key]
+	if !shared {
+		c = &call[T]{done: make(chan struct{})}
+		g.calls[key] = c
+
+		go func() {
+			c.value, c.err = fn(context.WithoutCancel(ctx))
+
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+
+			close(c.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, shared, c.err
+	case <-ctx.Done():
+		return value, shared, ctx.Err()
+	}
+}
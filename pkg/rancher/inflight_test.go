@@ -0,0 +1,113 @@
+package rancher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestFlightGroup_SharesConcurrentCalls(t *testing.T) {
+	g := newFlightGroup[string]()
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	fn := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "p-abc123", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, shared, err := g.do(context.Background(), "key", fn)
+			if err != nil || value != "p-abc123" {
+				t.Errorf("unexpected result: %q, %v", value, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+
+	// Give every caller time to join before letting the lookup finish
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 call, got %d", got)
+	}
+	if got := sharedCount.Load(); got != callers-1 {
+		t.Errorf("expected %d shared callers, got %d", callers-1, got)
+	}
+
+	// Completed calls are forgotten so later misses query again
+	if _, shared, _ := g.do(context.Background(), "key", func(ctx context.Context) (string, error) { return "", nil }); shared {
+		t.Error("expected a new call after the previous one completed")
+	}
+}
+
+func TestFlightGroup_CallerCancellation(t *testing.T) {
+	g := newFlightGroup[string]()
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := g.do(ctx, "key", func(ctx context.Context) (string, error) {
+		<-release
+		return "p-abc123", nil
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetProjectID_CoalescesConcurrentMisses(t *testing.T) {
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}: "ProjectList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind,
+		newTestProject("p-abc123", "c-m-cluster1", "platform"),
+	)
+
+	// Slow down List so concurrent misses overlap
+	var lists atomic.Int32
+	dynamicClient.PrependReactor("list", "projects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		return false, nil, nil
+	})
+
+	client := NewClient(dynamicClient, newTestLogger(), testClientConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			projectID, err := client.GetProjectID(context.Background(), "c-m-cluster1", "platform")
+			if err != nil || projectID != "p-abc123" {
+				t.Errorf("unexpected result: %q, %v", projectID, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := lists.Load(); got != 1 {
+		t.Errorf("expected 1 List call for 50 concurrent misses, got %d", got)
+	}
+}
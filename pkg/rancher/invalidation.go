@@ -0,0 +1,339 @@
+package rancher
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchRetryDelay is how long to wait before restarting a failed cache watch
+const watchRetryDelay = 5 * time.Second
+
+// cacheHandler applies changes to one kind of watched object to the caches
+type cacheHandler struct {
+	kind string
+	// changed handles added and updated objects
+	changed func(obj *unstructured.Unstructured)
+	deleted func(obj *unstructured.Unstructured)
+}
+
+// eventHandler adapts the handler to informers
+func (h cacheHandler) eventHandler() cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if u := toUnstructured(obj); u != nil {
+				h.changed(u)
+			}
+		},
+		UpdateFunc: func(_, newObj any) {
+			if u := toUnstructured(newObj); u != nil {
+				h.changed(u)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if u := toUnstructured(obj); u != nil {
+				h.deleted(u)
+			}
+		},
+	}
+}
+
+// registerInvalidationHandlers keeps the TTL and not-found caches in sync with
+// the informers. Lookups are served from the index once it has synced; until
+// then, and for projects created moments ago, they fall back to the caches.
+func (c *Client) registerInvalidationHandlers() error {
+	if _, err := c.index.clusters.AddEventHandler(c.clusterHandler().eventHandler()); err != nil {
+		return err
+	}
+	if c.index.managementClusters != nil {
+		if _, err := c.index.managementClusters.AddEventHandler(c.managementClusterHandler().eventHandler()); err != nil {
+			return err
+		}
+	}
+	_, err := c.index.projects.AddEventHandler(c.projectHandler().eventHandler())
+	return err
+}
+
+// cacheWatch is a resource watched to keep the caches in sync
+type cacheWatch struct {
+	resource dynamic.ResourceInterface
+	handler  cacheHandler
+}
+
+// StartWatches keeps the caches in sync when informers are disabled. It
+// watches clusters and projects without keeping them in memory, so renamed,
+// deleted and newly created objects take effect immediately instead of when
+// their cache entries expire. The watches run until ctx is canceled.
+func (c *Client) StartWatches(ctx context.Context) {
+	watches := []cacheWatch{
+		{c.dynamicClient.Resource(clusterGVR).Namespace(c.workspaces.namespace()), c.clusterHandler()},
+		{c.dynamicClient.Resource(projectGVR), c.projectHandler()},
+	}
+	if c.usesManagementClusters() {
+		watches = append(watches, cacheWatch{c.dynamicClient.Resource(managementClusterGVR), c.managementClusterHandler()})
+	}
+
+	for _, w := range watches {
+		// The first watch starts before returning, so no change made after
+		// StartWatches returns is missed
+		watcher, resourceVersion := c.startWatch(ctx, w.resource, w.handler.kind, "")
+		go c.runWatch(ctx, w.resource, w.handler, watcher, resourceVersion)
+	}
+}
+
+// startWatch watches resource from resourceVersion, or from now when it is
+// empty so existing objects aren't replayed. It returns a nil watcher when the
+// watch couldn't be started, and the resource version it started from.
+func (c *Client) startWatch(ctx context.Context, resource dynamic.ResourceInterface, kind, resourceVersion string) (watch.Interface, string) {
+	if resourceVersion == "" {
+		list, err := resource.List(ctx, metav1.ListOptions{Limit: 1})
+		if err != nil {
+			c.logger.Warn("Failed to list for cache watch",
+				slog.String("kind", kind),
+				slog.String("error", err.Error()),
+			)
+			return nil, ""
+		}
+		resourceVersion = list.GetResourceVersion()
+	}
+
+	watcher, err := resource.Watch(ctx, metav1.ListOptions{
+		ResourceVersion:     resourceVersion,
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		c.logger.Warn("Failed to start cache watch",
+			slog.String("kind", kind),
+			slog.String("error", err.Error()),
+		)
+		if errors.IsGone(err) || errors.IsResourceExpired(err) {
+			c.missedEvents(kind)
+			return nil, ""
+		}
+		return nil, resourceVersion
+	}
+	return watcher, resourceVersion
+}
+
+// runWatch applies watch events to the caches and restarts the watch when it
+// ends, until ctx is canceled
+func (c *Client) runWatch(ctx context.Context, resource dynamic.ResourceInterface, handler cacheHandler, watcher watch.Interface, resourceVersion string) {
+	for {
+		if watcher != nil {
+			resourceVersion = c.handleEvents(watcher, handler, resourceVersion)
+			watcher.Stop()
+		} else {
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryDelay):
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		watcher, resourceVersion = c.startWatch(ctx, resource, handler.kind, resourceVersion)
+	}
+}
+
+// handleEvents applies events until the watch ends, and returns the resource
+// version to resume from ("" when events were missed)
+func (c *Client) handleEvents(watcher watch.Interface, handler cacheHandler, resourceVersion string) string {
+	for event := range watcher.ResultChan() {
+		if event.Type == watch.Error {
+			// Usually the resource version is too old to resume from
+			c.logger.Warn("Cache watch failed",
+				slog.String("kind", handler.kind),
+				slog.String("error", errors.FromObject(event.Object).Error()),
+			)
+			c.missedEvents(handler.kind)
+			return ""
+		}
+
+		obj, ok := event.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		resourceVersion = obj.GetResourceVersion()
+
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			handler.changed(obj)
+		case watch.Deleted:
+			handler.deleted(obj)
+		}
+	}
+	return resourceVersion
+}
+
+// missedEvents clears the caches when watch events may have been missed,
+// since any entry may have become stale in the meantime
+func (c *Client) missedEvents(kind string) {
+	c.logger.Warn("Cache watch events may have been missed, clearing caches", slog.String("kind", kind))
+	c.ClearCache()
+}
+
+// clusterHandler handles provisioning clusters, cached by name
+func (c *Client) clusterHandler() cacheHandler {
+	return cacheHandler{
+		kind: "cluster",
+		changed: func(cluster *unstructured.Unstructured) {
+			if !c.workspaces.contains(cluster.GetNamespace()) {
+				return
+			}
+			name, clusterID := cluster.GetName(), clusterIDOf(cluster)
+			if clusterID != "" {
+				c.clearNegative("cluster:" + name)
+			}
+			c.invalidateClusters(metrics.InvalidationReasonUpdated, func(key, value string) bool {
+				return key == name && value != clusterID && c.clusterSource(key) != ClusterSourceManagement
+			})
+		},
+		deleted: func(cluster *unstructured.Unstructured) {
+			if !c.workspaces.contains(cluster.GetNamespace()) {
+				return
+			}
+			c.invalidateClusters(metrics.InvalidationReasonDeleted, func(key, _ string) bool {
+				return key == cluster.GetName()
+			})
+		},
+	}
+}
+
+// managementClusterHandler handles management clusters, cached by ID and display name
+func (c *Client) managementClusterHandler() cacheHandler {
+	return cacheHandler{
+		kind: "management_cluster",
+		changed: func(cluster *unstructured.Unstructured) {
+			clusterID, displayName := cluster.GetName(), displayNameOf(cluster)
+			c.clearNegative("cluster:" + clusterID)
+			if displayName != "" {
+				c.clearNegative("cluster:" + displayName)
+			}
+			// Names resolved only from provisioning clusters are left to the cluster handler
+			c.invalidateClusters(metrics.InvalidationReasonUpdated, func(key, value string) bool {
+				return value == clusterID && key != clusterID && key != displayName &&
+					c.clusterSource(key) != ClusterSourceProvisioning
+			})
+		},
+		deleted: func(cluster *unstructured.Unstructured) {
+			c.invalidateClusters(metrics.InvalidationReasonDeleted, func(_, value string) bool {
+				return value == cluster.GetName()
+			})
+		},
+	}
+}
+
+// projectHandler handles projects, cached by display name and by ID
+func (c *Client) projectHandler() cacheHandler {
+	return cacheHandler{
+		kind: "project",
+		changed: func(project *unstructured.Unstructured) {
+			clusterID, projectID := project.GetNamespace(), project.GetName()
+			displayNameKey := clusterID + ":" + displayNameOf(project)
+			c.clearNegative("project:"+displayNameKey, "project:"+projectIDCacheKey(clusterID, projectID))
+			c.invalidateProjects(metrics.InvalidationReasonUpdated, func(key, value string) bool {
+				return value == projectID && strings.HasPrefix(key, clusterID+":") && key != displayNameKey
+			})
+			// Quotas, labels or annotations for namespaces may have changed
+			c.invalidateNamespaceDefaults(clusterID, projectID)
+		},
+		deleted: func(project *unstructured.Unstructured) {
+			clusterID, projectID := project.GetNamespace(), project.GetName()
+			idKey := projectIDCacheKey(clusterID, projectID)
+			c.invalidateProjects(metrics.InvalidationReasonDeleted, func(key, value string) bool {
+				return key == idKey || (value == projectID && strings.HasPrefix(key, clusterID+":"))
+			})
+			c.invalidateNamespaceDefaults(clusterID, projectID)
+		},
+	}
+}
+
+// invalidateClusters evicts the cluster cache entries for which stale returns true
+func (c *Client) invalidateClusters(reason string, stale func(clusterName, clusterID string) bool) {
+	var evicted []string
+	c.clusterMu.Lock()
+	for clusterName, entry := range c.clusterCache {
+		if stale(clusterName, entry.value) {
+			delete(c.clusterCache, clusterName)
+			evicted = append(evicted, clusterName)
+		}
+	}
+	c.clusterMu.Unlock()
+
+	for _, clusterName := range evicted {
+		metrics.CacheInvalidationsTotal.WithLabelValues(metrics.CacheTypeCluster, reason).Inc()
+		c.logger.Info("Cluster cache entry invalidated",
+			slog.String("cluster", clusterName),
+			slog.String("reason", reason),
+		)
+	}
+}
+
+// invalidateProjects evicts the project cache entries for which stale returns true
+func (c *Client) invalidateProjects(reason string, stale func(key, projectID string) bool) {
+	var evicted []string
+	c.projectMu.Lock()
+	for key, entry := range c.projectCache {
+		if stale(key, entry.value) {
+			delete(c.projectCache, key)
+			evicted = append(evicted, key)
+		}
+	}
+	c.projectMu.Unlock()
+
+	for _, key := range evicted {
+		metrics.CacheInvalidationsTotal.WithLabelValues(metrics.CacheTypeProject, reason).Inc()
+		c.logger.Info("Project cache entry invalidated",
+			slog.String("key", key),
+			slog.String("reason", reason),
+		)
+	}
+}
+
+// invalidateNamespaceDefaults evicts the namespace defaults of a project
+func (c *Client) invalidateNamespaceDefaults(clusterID, projectID string) {
+	c.defaultsMu.Lock()
+	delete(c.defaultsCache, clusterID+":"+projectID)
+	c.defaultsMu.Unlock()
+}
+
+// clearNegative removes not-found entries, e.g. when the object was just created
+func (c *Client) clearNegative(keys ...string) {
+	c.negativeMu.Lock()
+	defer c.negativeMu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := c.negativeCache[key]; ok {
+			delete(c.negativeCache, key)
+			c.logger.Debug("Not-found cache entry cleared", slog.String("key", key))
+		}
+	}
+}
+
+// toUnstructured unwraps informer objects, including delete tombstones
+func toUnstructured(obj any) *unstructured.Unstructured {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, _ := obj.(*unstructured.Unstructured)
+	return u
+}
+
+func clusterIDOf(cluster *unstructured.Unstructured) string {
+	clusterID, _, _ := unstructured.NestedString(cluster.Object, "status", "clusterName")
+	return clusterID
+}
+
+func displayNameOf(project *unstructured.Unstructured) string {
+	displayName, _, _ := unstructured.NestedString(project.Object, "spec", "displayName")
+	return displayName
+}
@@ -0,0 +1,263 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// waitForEviction polls until key is gone from the project cache
+func waitForEviction(t *testing.T, client *Client, key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.projectMu.RLock()
+		_, ok := client.projectCache[key]
+		client.projectMu.RUnlock()
+		if !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected project cache entry %q to be evicted", key)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestInvalidation_ProjectRenamed(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+	before := testutil.ToFloat64(metrics.CacheInvalidationsTotal.WithLabelValues(metrics.CacheTypeProject, metrics.InvalidationReasonUpdated))
+
+	client.projectMu.Lock()
+	client.projectCache["c-m-cluster1:platform"] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectMu.Unlock()
+
+	renamed := newTestProject("p-abc123", "c-m-cluster1", "platform-v2")
+	if _, err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Update(context.Background(), renamed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to rename project: %v", err)
+	}
+
+	waitForEviction(t, client, "c-m-cluster1:platform")
+
+	after := testutil.ToFloat64(metrics.CacheInvalidationsTotal.WithLabelValues(metrics.CacheTypeProject, metrics.InvalidationReasonUpdated))
+	if after-before != 1 {
+		t.Errorf("expected 1 project invalidation, got %f", after-before)
+	}
+}
+
+func TestInvalidation_ProjectDeleted(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	client.projectMu.Lock()
+	client.projectCache["c-m-cluster1:platform"] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectMu.Unlock()
+
+	if err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Delete(context.Background(), "p-abc123", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	waitForEviction(t, client, "c-m-cluster1:platform")
+}
+
+func TestInvalidation_ClusterDeleted(t *testing.T) {
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "provisioning.cattle.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "test-cluster",
+				"namespace": "fleet-default",
+			},
+			"status": map[string]any{
+				"clusterName": "c-m-abc123",
+			},
+		},
+	}
+	client, dynamicClient := newTestIndexedClient(t, cluster)
+
+	client.clusterMu.Lock()
+	client.clusterCache["test-cluster"] = cacheEntry{value: "c-m-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.clusterMu.Unlock()
+
+	if err := dynamicClient.Resource(clusterGVR).Namespace("fleet-default").Delete(context.Background(), "test-cluster", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete cluster: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.clusterMu.RLock()
+		_, ok := client.clusterCache["test-cluster"]
+		client.clusterMu.RUnlock()
+		if !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected cluster cache entry to be evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "test-cluster"); err == nil {
+		t.Error("expected deleted cluster to no longer resolve")
+	}
+}
+
+func TestInvalidation_ManagementClusterRenamed(t *testing.T) {
+	client, dynamicClient := newTestIndexedClientWithConfig(t,
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, ClusterSource: ClusterSourceManagement},
+		newTestManagementCluster("c-legacy", "imported-cluster"),
+	)
+
+	client.clusterMu.Lock()
+	client.clusterCache["imported-cluster"] = cacheEntry{value: "c-legacy", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.clusterMu.Unlock()
+
+	renamed := newTestManagementCluster("c-legacy", "imported-cluster-v2")
+	if _, err := dynamicClient.Resource(managementClusterGVR).Update(context.Background(), renamed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to rename management cluster: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.clusterMu.RLock()
+		_, ok := client.clusterCache["imported-cluster"]
+		client.clusterMu.RUnlock()
+		if !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected cluster cache entry to be evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForNegativeEviction polls until key is gone from the not-found cache
+func waitForNegativeEviction(t *testing.T, client *Client, key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client.negativeMu.RLock()
+		_, ok := client.negativeCache[key]
+		client.negativeMu.RUnlock()
+		if !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected not-found cache entry %q to be cleared", key)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newTestWatchedClient returns a client without informers whose caches are kept in sync by watches
+func newTestWatchedClient(t *testing.T, objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		clusterGVR:           "ClusterList",
+		projectGVR:           "ProjectList",
+		managementClusterGVR: "ClusterList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, objects...)
+	client := NewClient(dynamicClient, newTestLogger(), testClientConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	client.StartWatches(ctx)
+
+	return client, dynamicClient
+}
+
+func TestInvalidation_ProjectCreatedClearsNegativeCache(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t)
+	client.setNegative("project:c-m-cluster1:payments", notFound(fmt.Errorf("project payments not found")))
+
+	if _, err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Create(context.Background(), newTestProject("p-abc123", "c-m-cluster1", "payments"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	waitForNegativeEviction(t, client, "project:c-m-cluster1:payments")
+}
+
+func TestWatches_ProjectRenamed(t *testing.T) {
+	client, dynamicClient := newTestWatchedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	client.projectMu.Lock()
+	client.projectCache["c-m-cluster1:platform"] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectCache["c-m-cluster1:other"] = cacheEntry{value: "p-other", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectMu.Unlock()
+
+	renamed := newTestProject("p-abc123", "c-m-cluster1", "platform-v2")
+	if _, err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Update(context.Background(), renamed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to rename project: %v", err)
+	}
+
+	waitForEviction(t, client, "c-m-cluster1:platform")
+
+	client.projectMu.RLock()
+	_, ok := client.projectCache["c-m-cluster1:other"]
+	client.projectMu.RUnlock()
+	if !ok {
+		t.Error("expected unrelated project cache entry to be kept")
+	}
+}
+
+func TestWatches_ProjectDeleted(t *testing.T) {
+	client, dynamicClient := newTestWatchedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	client.projectMu.Lock()
+	client.projectCache["c-m-cluster1:platform"] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectCache[projectIDCacheKey("c-m-cluster1", "p-abc123")] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectMu.Unlock()
+
+	if err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Delete(context.Background(), "p-abc123", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	waitForEviction(t, client, "c-m-cluster1:platform")
+	waitForEviction(t, client, projectIDCacheKey("c-m-cluster1", "p-abc123"))
+}
+
+func TestWatches_ClusterCreatedClearsNegativeCache(t *testing.T) {
+	client, dynamicClient := newTestWatchedClient(t)
+	client.setNegative("cluster:new-cluster", notFound(fmt.Errorf("cluster new-cluster not found")))
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "provisioning.cattle.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "new-cluster",
+				"namespace": "fleet-default",
+			},
+			"status": map[string]any{
+				"clusterName": "c-m-new",
+			},
+		},
+	}
+	if _, err := dynamicClient.Resource(clusterGVR).Namespace("fleet-default").Create(context.Background(), cluster, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+
+	waitForNegativeEviction(t, client, "cluster:new-cluster")
+
+	clusterID, err := client.GetClusterID(context.Background(), "new-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-m-new" {
+		t.Errorf("expected cluster ID 'c-m-new', got '%s'", clusterID)
+	}
+}
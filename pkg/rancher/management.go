@@ -0,0 +1,184 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var managementClusterGVR = schema.GroupVersionResource{
+	Group:    "management.cattle.io",
+	Version:  "v3",
+	Resource: "clusters",
+}
+
+// ClusterSource selects which Rancher resource a cluster name is resolved from
+type ClusterSource string
+
+const (
+	// ClusterSourceAuto resolves provisioning clusters and falls back to
+	// management clusters when there is none
+	ClusterSourceAuto ClusterSource = "auto"
+	// ClusterSourceProvisioning resolves clusters.provisioning.cattle.io by name
+	ClusterSourceProvisioning ClusterSource = "provisioning"
+	// ClusterSourceManagement resolves clusters.management.cattle.io by ID
+	// (c-xxxxx) or spec.displayName, for imported and RKE1 clusters
+	ClusterSourceManagement ClusterSource = "management"
+)
+
+// ParseClusterSource validates a cluster source name
+func ParseClusterSource(s string) (ClusterSource, error) {
+	switch source := ClusterSource(strings.TrimSpace(s)); source {
+	case ClusterSourceAuto, ClusterSourceProvisioning, ClusterSourceManagement:
+		return source, nil
+	default:
+		return "", fmt.Errorf("invalid cluster source %q (must be auto, provisioning or management)", s)
+	}
+}
+
+// ParseClusterSources parses per-cluster overrides of the form
+// "cluster-a=management,cluster-b=provisioning"
+func ParseClusterSources(s string) (map[string]ClusterSource, error) {
+	sources := make(map[string]ClusterSource)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		clusterName, value, found := strings.Cut(pair, "=")
+		clusterName = strings.TrimSpace(clusterName)
+		if !found || clusterName == "" {
+			return nil, fmt.Errorf("invalid cluster source override %q (expected cluster=source)", pair)
+		}
+
+		source, err := ParseClusterSource(value)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", clusterName, err)
+		}
+		sources[clusterName] = source
+	}
+	return sources, nil
+}
+
+// clusterSource returns the source a cluster name is resolved from
+func (c *Client) clusterSource(clusterName string) ClusterSource {
+	if source, ok := c.clusterSources[clusterName]; ok {
+		return source
+	}
+	return c.defaultClusterSource
+}
+
+// usesManagementClusters reports whether any cluster may be resolved from
+// management clusters, which needs access to clusters.management.cattle.io
+func (c *Client) usesManagementClusters() bool {
+	if c.defaultClusterSource != ClusterSourceProvisioning {
+		return true
+	}
+	for _, source := range c.clusterSources {
+		if source != ClusterSourceProvisioning {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchManagementClusterID resolves a management cluster by ID or display name with retries
+func (c *Client) fetchManagementClusterID(ctx context.Context, clusterName string) (string, error) {
+	var clusterID string
+	var err error
+	backoff := initialBackoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		clusterID, err = c.getManagementClusterID(ctx, clusterName)
+		if err == nil {
+			return clusterID, nil
+		}
+
+		if isAmbiguous(err) {
+			return "", err
+		}
+
+		if errors.IsNotFound(err) {
+			return "", notFound(fmt.Errorf("management cluster %s not found by ID or display name", clusterName))
+		}
+
+		if !isRetryableError(err) || attempt == maxRetries {
+			metrics.ClusterLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return "", fmt.Errorf("failed to get management cluster %s: %w", clusterName, err)
+		}
+
+		c.logger.Debug("Retrying management cluster lookup",
+			slog.String("cluster", clusterName),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	return "", err
+}
+
+// getManagementClusterID reads a management cluster by ID, then by display
+// name. It returns a NotFound error when neither matches.
+func (c *Client) getManagementClusterID(ctx context.Context, clusterName string) (string, error) {
+	clusters := c.dynamicClient.Resource(managementClusterGVR)
+
+	cluster, err := clusters.Get(ctx, clusterName, metav1.GetOptions{})
+	if err == nil {
+		return cluster.GetName(), nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	list, err := clusters.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	candidates := make([]*unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		candidates = append(candidates, &list.Items[i])
+	}
+
+	return selectManagementCluster(clusterName, candidates)
+}
+
+// selectManagementCluster returns the ID of the single management cluster
+// whose display name is clusterName
+func selectManagementCluster(clusterName string, candidates []*unstructured.Unstructured) (string, error) {
+	var ids []string
+	for _, cluster := range candidates {
+		if displayNameOf(cluster) == clusterName {
+			ids = append(ids, cluster.GetName())
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", errors.NewNotFound(managementClusterGVR.GroupResource(), clusterName)
+	case 1:
+		return ids[0], nil
+	}
+
+	slices.Sort(ids)
+	return "", fmt.Errorf("%w: %s is the display name of management clusters %s", ErrAmbiguousCluster, clusterName, strings.Join(ids, ", "))
+}
@@ -0,0 +1,192 @@
+package rancher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestManagementCluster(id, displayName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "management.cattle.io/v3",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name": id,
+			},
+			"spec": map[string]any{
+				"displayName": displayName,
+			},
+		},
+	}
+}
+
+func TestParseClusterSource(t *testing.T) {
+	for _, valid := range []string{"auto", "provisioning", " management "} {
+		if _, err := ParseClusterSource(valid); err != nil {
+			t.Errorf("unexpected error for %q: %v", valid, err)
+		}
+	}
+	if _, err := ParseClusterSource("legacy"); err == nil {
+		t.Error("expected error for unknown cluster source")
+	}
+}
+
+func TestParseClusterSources(t *testing.T) {
+	sources, err := ParseClusterSources("legacy-a=management, prov-b = provisioning,,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sources) != 2 || sources["legacy-a"] != ClusterSourceManagement || sources["prov-b"] != ClusterSourceProvisioning {
+		t.Errorf("unexpected sources: %v", sources)
+	}
+
+	for _, invalid := range []string{"legacy-a", "=management", "legacy-a=rke1"} {
+		if _, err := ParseClusterSources(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestGetClusterID_ManagementSource(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, ClusterSource: ClusterSourceManagement},
+		newTestManagementCluster("c-abc12", "legacy-cluster"),
+	)
+
+	tests := []struct {
+		name        string
+		clusterName string
+	}{
+		{name: "by ID", clusterName: "c-abc12"},
+		{name: "by display name", clusterName: "legacy-cluster"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterID, err := client.GetClusterID(context.Background(), tt.clusterName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if clusterID != "c-abc12" {
+				t.Errorf("expected cluster ID 'c-abc12', got '%s'", clusterID)
+			}
+		})
+	}
+}
+
+func TestGetClusterID_AutoSource(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, ClusterSource: ClusterSourceAuto},
+		newTestCluster("prov-cluster", "fleet-default", "c-m-prov"),
+		newTestManagementCluster("c-m-prov", "prov-cluster"),
+		newTestManagementCluster("c-legacy", "imported-cluster"),
+	)
+
+	tests := []struct {
+		clusterName string
+		want        string
+	}{
+		{clusterName: "prov-cluster", want: "c-m-prov"},
+		{clusterName: "imported-cluster", want: "c-legacy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clusterName, func(t *testing.T) {
+			clusterID, err := client.GetClusterID(context.Background(), tt.clusterName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if clusterID != tt.want {
+				t.Errorf("expected cluster ID %q, got %q", tt.want, clusterID)
+			}
+		})
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "missing"); err == nil {
+		t.Error("expected error for cluster missing from both sources")
+	}
+}
+
+func TestGetClusterID_ProvisioningSourceDoesNotFallBack(t *testing.T) {
+	client, dynamicClient := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL},
+		newTestManagementCluster("c-legacy", "imported-cluster"),
+	)
+
+	if _, err := client.GetClusterID(context.Background(), "imported-cluster"); err == nil {
+		t.Fatal("expected error for cluster without a provisioning object")
+	}
+
+	for _, action := range dynamicClient.Actions() {
+		if action.GetResource() == managementClusterGVR {
+			t.Errorf("expected no management cluster calls, got %s", action.GetVerb())
+		}
+	}
+}
+
+func TestGetClusterID_PerClusterSource(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{
+			CacheTTL:       testClientConfig().CacheTTL,
+			ClusterSources: map[string]ClusterSource{"imported-cluster": ClusterSourceManagement},
+		},
+		newTestManagementCluster("c-legacy", "imported-cluster"),
+	)
+
+	if !client.usesManagementClusters() {
+		t.Error("expected a management override to require management clusters")
+	}
+
+	clusterID, err := client.GetClusterID(context.Background(), "imported-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-legacy" {
+		t.Errorf("expected cluster ID 'c-legacy', got '%s'", clusterID)
+	}
+}
+
+func TestGetClusterID_ManagementAmbiguousDisplayName(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, ClusterSource: ClusterSourceManagement},
+		newTestManagementCluster("c-aaaaa", "duplicate"),
+		newTestManagementCluster("c-bbbbb", "duplicate"),
+	)
+
+	_, err := client.GetClusterID(context.Background(), "duplicate")
+	if !errors.Is(err, ErrAmbiguousCluster) {
+		t.Fatalf("expected ErrAmbiguousCluster, got %v", err)
+	}
+	if want := "ambiguous cluster name: duplicate is the display name of management clusters c-aaaaa, c-bbbbb"; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+}
+
+func TestGetClusterID_FromIndexManagementSource(t *testing.T) {
+	client, _ := newTestIndexedClientWithConfig(t,
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, ClusterSource: ClusterSourceAuto},
+		newTestCluster("prov-cluster", "fleet-default", "c-m-prov"),
+		newTestManagementCluster("c-legacy", "imported-cluster"),
+	)
+
+	for clusterName, want := range map[string]string{
+		"prov-cluster":     "c-m-prov",
+		"imported-cluster": "c-legacy",
+		"c-legacy":         "c-legacy",
+	} {
+		clusterID, err := client.GetClusterID(context.Background(), clusterName)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", clusterName, err)
+		}
+		if clusterID != want {
+			t.Errorf("expected cluster ID %q for %s, got %q", want, clusterName, clusterID)
+		}
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "missing"); err == nil {
+		t.Error("expected error for cluster missing from index")
+	}
+}
@@ -0,0 +1,170 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var projectRoleTemplateBindingGVR = schema.GroupVersionResource{
+	Group:    "management.cattle.io",
+	Version:  "v3",
+	Resource: "projectroletemplatebindings",
+}
+
+// serviceAccountPrefix prefixes the usernames of Kubernetes service accounts
+const serviceAccountPrefix = "system:serviceaccount:"
+
+// projectMembers are the subjects a project's role template bindings grant access to
+type projectMembers struct {
+	// users holds Rancher user IDs (u-xxxxx) and user principal names
+	users []string
+	// groups holds group names and group principal names
+	groups []string
+	// serviceAccounts holds "namespace:name" of service accounts
+	serviceAccounts []string
+}
+
+// memberSnapshot is the members of every project, keyed by "clusterID:projectID"
+type memberSnapshot struct {
+	projects  map[string]*projectMembers
+	expiresAt time.Time
+}
+
+// IsProjectMember reports whether a project role template binding grants a
+// user, one of its groups, or a service account access to a project. The
+// user is identified as Rancher impersonates it downstream: its Rancher user
+// ID or principal name, and its group principal names.
+func (c *Client) IsProjectMember(ctx context.Context, clusterID, projectID, username string, groups []string) (bool, error) {
+	snapshot, err := c.memberSnapshot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	members, ok := snapshot.projects[clusterID+":"+projectID]
+	if !ok {
+		return false, nil
+	}
+
+	if slices.Contains(members.users, username) {
+		return true, nil
+	}
+	for _, group := range groups {
+		if slices.Contains(members.groups, group) {
+			return true, nil
+		}
+	}
+	if serviceAccount, ok := strings.CutPrefix(username, serviceAccountPrefix); ok {
+		return slices.Contains(members.serviceAccounts, serviceAccount), nil
+	}
+	return false, nil
+}
+
+// memberSnapshot returns the cached project members, listing all project role
+// template bindings once the cache has expired
+func (c *Client) memberSnapshot(ctx context.Context) (*memberSnapshot, error) {
+	c.membersMu.RLock()
+	snapshot := c.members
+	c.membersMu.RUnlock()
+
+	if snapshot != nil && time.Now().Before(snapshot.expiresAt) {
+		metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProjectMembers).Inc()
+		return snapshot, nil
+	}
+	metrics.CacheMissesTotal.WithLabelValues(metrics.CacheTypeProjectMembers).Inc()
+
+	// The snapshot is returned by the flight itself: the cache may be cleared
+	// before the callers get to read it
+	snapshot, shared, err := c.memberFlights.do(ctx, "", c.fetchProjectMembers)
+	if shared {
+		metrics.CoalescedRequestsTotal.WithLabelValues(metrics.CacheTypeProjectMembers).Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// fetchProjectMembers lists project role template bindings with retries and
+// caches and returns the members of every project
+func (c *Client) fetchProjectMembers(ctx context.Context) (*memberSnapshot, error) {
+	var list *unstructured.UnstructuredList
+	var err error
+	backoff := initialBackoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		list, err = c.dynamicClient.Resource(projectRoleTemplateBindingGVR).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			break
+		}
+
+		if !isRetryableError(err) || attempt == maxRetries {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return nil, fmt.Errorf("failed to list project role template bindings: %w", err)
+		}
+
+		c.logger.Debug("Retrying project role template binding list",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	snapshot := &memberSnapshot{
+		projects:  make(map[string]*projectMembers),
+		expiresAt: time.Now().Add(c.cacheTTL),
+	}
+	for _, binding := range list.Items {
+		projectName, _, _ := unstructured.NestedString(binding.Object, "projectName")
+		if projectName == "" {
+			continue
+		}
+
+		members, ok := snapshot.projects[projectName]
+		if !ok {
+			members = &projectMembers{}
+			snapshot.projects[projectName] = members
+		}
+		members.users = appendFields(members.users, binding.Object, "userName", "userPrincipalName")
+		members.groups = appendFields(members.groups, binding.Object, "groupName", "groupPrincipalName")
+		members.serviceAccounts = appendFields(members.serviceAccounts, binding.Object, "serviceAccount")
+	}
+
+	c.membersMu.Lock()
+	c.members = snapshot
+	c.membersMu.Unlock()
+
+	c.logger.Debug("Project members listed and cached",
+		slog.Int("bindings", len(list.Items)),
+		slog.Int("projects", len(snapshot.projects)),
+		slog.Duration("ttl", c.cacheTTL),
+	)
+
+	return snapshot, nil
+}
+
+// appendFields appends the non-empty top-level string fields of obj to values
+func appendFields(values []string, obj map[string]any, fields ...string) []string {
+	for _, field := range fields {
+		if value, _, _ := unstructured.NestedString(obj, field); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
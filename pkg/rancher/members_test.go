@@ -0,0 +1,84 @@
+package rancher
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestProjectRoleTemplateBinding(namespace, name, projectName string, subject map[string]any) *unstructured.Unstructured {
+	obj := map[string]any{
+		"apiVersion": "management.cattle.io/v3",
+		"kind":       "ProjectRoleTemplateBinding",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"projectName":      projectName,
+		"roleTemplateName": "project-member",
+	}
+	for k, v := range subject {
+		obj[k] = v
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestIsProjectMember(t *testing.T) {
+	client, _ := newTestWorkspaceClient(testClientConfig(),
+		newTestProjectRoleTemplateBinding("p-abc12", "prtb-user", "c-m-abc123:p-abc12", map[string]any{"userName": "u-alice"}),
+		newTestProjectRoleTemplateBinding("p-abc12", "prtb-group", "c-m-abc123:p-abc12", map[string]any{"groupPrincipalName": "github_team://42"}),
+		newTestProjectRoleTemplateBinding("c-m-abc123-p-abc12", "prtb-sa", "c-m-abc123:p-abc12", map[string]any{"serviceAccount": "argocd:argocd-application-controller"}),
+		newTestProjectRoleTemplateBinding("p-other", "prtb-other", "c-m-abc123:p-other", map[string]any{"userName": "u-bob"}),
+	)
+
+	tests := []struct {
+		name      string
+		projectID string
+		username  string
+		groups    []string
+		expected  bool
+	}{
+		{name: "bound user", projectID: "p-abc12", username: "u-alice", expected: true},
+		{name: "bound group", projectID: "p-abc12", username: "u-carol", groups: []string{"system:authenticated", "github_team://42"}, expected: true},
+		{name: "bound service account", projectID: "p-abc12", username: "system:serviceaccount:argocd:argocd-application-controller", expected: true},
+		{name: "member of another project", projectID: "p-abc12", username: "u-bob", expected: false},
+		{name: "project without bindings", projectID: "p-none1", username: "u-alice", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member, err := client.IsProjectMember(context.Background(), "c-m-abc123", tt.projectID, tt.username, tt.groups)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if member != tt.expected {
+				t.Errorf("expected member=%v, got %v", tt.expected, member)
+			}
+		})
+	}
+}
+
+func TestIsProjectMember_CachesBindings(t *testing.T) {
+	client, dynamicClient := newTestWorkspaceClient(testClientConfig(),
+		newTestProjectRoleTemplateBinding("p-abc12", "prtb-user", "c-m-abc123:p-abc12", map[string]any{"userName": "u-alice"}),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.IsProjectMember(context.Background(), "c-m-abc123", "p-abc12", "u-alice", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := countActions(dynamicClient, "list", "projectroletemplatebindings"); got != 1 {
+		t.Errorf("expected 1 list of bindings, got %d", got)
+	}
+
+	client.ClearCache()
+	if _, err := client.IsProjectMember(context.Background(), "c-m-abc123", "p-abc12", "u-alice", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := countActions(dynamicClient, "list", "projectroletemplatebindings"); got != 2 {
+		t.Errorf("expected bindings to be listed again after ClearCache, got %d lists", got)
+	}
+}
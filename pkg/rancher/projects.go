@@ -0,0 +1,133 @@
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// projectIDCacheKey keys verified project IDs in the project cache. Cluster
+// IDs never contain "/", so these keys can't collide with display name keys.
+func projectIDCacheKey(clusterID, projectID string) string {
+	return "id/" + clusterID + ":" + projectID
+}
+
+// ProjectExists reports whether a project with the given ID (e.g., p-xxxxx) exists in a cluster
+func (c *Client) ProjectExists(ctx context.Context, clusterID, projectID string) (bool, error) {
+	if c.indexReady() {
+		exists, err := c.index.projectExists(clusterID, projectID)
+		if err != nil {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return false, fmt.Errorf("failed to get project %s from index: %w", projectID, err)
+		}
+		if !exists {
+			// A project created moments ago may not have reached the informers yet
+			_, exists = c.cachedProject(projectIDCacheKey(clusterID, projectID))
+		}
+		if exists {
+			metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+		}
+		return exists, nil
+	}
+
+	cacheKey := projectIDCacheKey(clusterID, projectID)
+
+	// Check cache first
+	c.projectMu.RLock()
+	if entry, ok := c.projectCache[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+		c.projectMu.RUnlock()
+		c.logger.Debug("Project ID verification cache hit",
+			slog.String("cluster_id", clusterID),
+			slog.String("project_id", projectID),
+		)
+		metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+		return true, nil
+	}
+	c.projectMu.RUnlock()
+
+	// Cache miss - query API, sharing the call with concurrent misses
+	metrics.CacheMissesTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+
+	if err := c.getNegative("project:"+cacheKey, metrics.CacheTypeProject); err != nil {
+		return false, nil
+	}
+
+	_, shared, err := c.projectFlights.do(ctx, cacheKey, func(ctx context.Context) (string, error) {
+		return c.fetchProjectByID(ctx, clusterID, projectID)
+	})
+	if shared {
+		metrics.CoalescedRequestsTotal.WithLabelValues(metrics.CacheTypeProject).Inc()
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// fetchProjectByID reads a project by ID with retries and caches it when it exists
+func (c *Client) fetchProjectByID(ctx context.Context, clusterID, projectID string) (string, error) {
+	cacheKey := projectIDCacheKey(clusterID, projectID)
+
+	var err error
+	backoff := initialBackoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		_, err = c.dynamicClient.Resource(projectGVR).Namespace(clusterID).Get(
+			ctx,
+			projectID,
+			metav1.GetOptions{},
+		)
+		if err == nil {
+			break
+		}
+
+		if errors.IsNotFound(err) {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
+			err = notFound(fmt.Errorf("project %s not found in cluster %s", projectID, clusterID))
+			c.setNegative("project:"+cacheKey, err)
+			return "", err
+		}
+
+		if !isRetryableError(err) || attempt == maxRetries {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return "", fmt.Errorf("failed to get project %s in cluster %s: %w", projectID, clusterID, err)
+		}
+
+		c.logger.Debug("Retrying project ID verification",
+			slog.String("cluster_id", clusterID),
+			slog.String("project_id", projectID),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	// Store in cache
+	c.projectMu.Lock()
+	c.projectCache[cacheKey] = cacheEntry{
+		value:     projectID,
+		expiresAt: time.Now().Add(c.cacheTTL),
+	}
+	c.projectMu.Unlock()
+
+	c.logger.Debug("Project ID verified and cached",
+		slog.String("cluster_id", clusterID),
+		slog.String("project_id", projectID),
+		slog.Duration("ttl", c.cacheTTL),
+	)
+
+	return projectID, nil
+}
@@ -0,0 +1,81 @@
+package rancher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestProjectExists(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newTestProject("p-abc123", "c-m-cluster1", "platform"))
+	client := NewClient(dynamicClient, newTestLogger(), testClientConfig())
+
+	exists, err := client.ProjectExists(context.Background(), "c-m-cluster1", "p-abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists {
+		t.Error("expected project to exist")
+	}
+	if _, ok := client.projectCache[projectIDCacheKey("c-m-cluster1", "p-abc123")]; !ok {
+		t.Error("expected verified project ID to be cached")
+	}
+
+	// A project ID from another cluster doesn't exist in this one
+	exists, err = client.ProjectExists(context.Background(), "c-m-cluster2", "p-abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists {
+		t.Error("expected project to not exist in another cluster")
+	}
+}
+
+func TestProjectExists_NegativeCache(t *testing.T) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	client := NewClient(dynamicClient, newTestLogger(), testClientConfig())
+
+	for i := 0; i < 3; i++ {
+		exists, err := client.ProjectExists(context.Background(), "c-m-cluster1", "p-missing")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if exists {
+			t.Fatal("expected project to not exist")
+		}
+	}
+
+	if got := countActions(dynamicClient, "get", "projects"); got != 1 {
+		t.Errorf("expected 1 API call for repeated not-found lookups, got %d", got)
+	}
+}
+
+func TestProjectExists_FromIndex(t *testing.T) {
+	client, _ := newTestIndexedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	if exists, err := client.ProjectExists(context.Background(), "c-m-cluster1", "p-abc123"); err != nil || !exists {
+		t.Errorf("expected project to exist, got exists=%v err=%v", exists, err)
+	}
+	if exists, err := client.ProjectExists(context.Background(), "c-m-cluster1", "p-missing"); err != nil || exists {
+		t.Errorf("expected project to not exist, got exists=%v err=%v", exists, err)
+	}
+}
+
+func TestInvalidation_ProjectDeletedEvictsVerifiedID(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t, newTestProject("p-abc123", "c-m-cluster1", "platform"))
+
+	key := projectIDCacheKey("c-m-cluster1", "p-abc123")
+	client.projectMu.Lock()
+	client.projectCache[key] = cacheEntry{value: "p-abc123", expiresAt: time.Now().Add(5 * time.Minute)}
+	client.projectMu.Unlock()
+
+	if err := dynamicClient.Resource(projectGVR).Namespace("c-m-cluster1").Delete(context.Background(), "p-abc123", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete project: %v", err)
+	}
+
+	waitForEviction(t, client, key)
+}
@@ -0,0 +1,233 @@
+package rancher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Project annotations carrying labels and annotations for the project's
+// namespaces. Keys with a prefix of their own (pod-security.kubernetes.io/enforce)
+// can't follow another prefix in an annotation key, so they go in the JSON maps.
+const (
+	// namespaceLabelPrefix precedes a label name, e.g. fencemaster.io/namespace-label.cost-center
+	namespaceLabelPrefix = "fencemaster.io/namespace-label."
+	// namespaceAnnotationPrefix precedes an annotation name
+	namespaceAnnotationPrefix = "fencemaster.io/namespace-annotation."
+	// namespaceLabelsAnnotation holds a JSON map of labels
+	namespaceLabelsAnnotation = "fencemaster.io/namespace-labels"
+	// namespaceAnnotationsAnnotation holds a JSON map of annotations
+	namespaceAnnotationsAnnotation = "fencemaster.io/namespace-annotations"
+)
+
+// namespaceDefaults are what a project prescribes for its namespaces: its
+// namespace default resource quota and container default resource limit, as
+// the JSON of the namespace annotations Rancher expects ("" when the project
+// has none), and the labels and annotations set on the project for them
+type namespaceDefaults struct {
+	resourceQuota  string
+	containerLimit string
+	labels         map[string]string
+	annotations    map[string]string
+	expiresAt      time.Time
+}
+
+// GetNamespaceDefaults returns the values of the field.cattle.io/resourceQuota
+// and field.cattle.io/containerDefaultResourceLimit annotations for namespaces
+// of a project: the JSON of its spec.namespaceDefaultResourceQuota and
+// spec.containerDefaultResourceLimit, or "" for those it doesn't set.
+func (c *Client) GetNamespaceDefaults(ctx context.Context, clusterID, projectID string) (string, string, error) {
+	defaults, err := c.namespaceDefaults(ctx, clusterID, projectID)
+	return defaults.resourceQuota, defaults.containerLimit, err
+}
+
+// GetNamespaceMetadata returns the labels and annotations a project sets for
+// its namespaces, from project annotations of the form
+// fencemaster.io/namespace-label.NAME and fencemaster.io/namespace-annotation.NAME,
+// and the JSON maps in fencemaster.io/namespace-labels and
+// fencemaster.io/namespace-annotations. Prefixed keys win over map entries.
+func (c *Client) GetNamespaceMetadata(ctx context.Context, clusterID, projectID string) (map[string]string, map[string]string, error) {
+	defaults, err := c.namespaceDefaults(ctx, clusterID, projectID)
+	return maps.Clone(defaults.labels), maps.Clone(defaults.annotations), err
+}
+
+// namespaceDefaults returns a project's namespace defaults from the index,
+// the cache or the API
+func (c *Client) namespaceDefaults(ctx context.Context, clusterID, projectID string) (namespaceDefaults, error) {
+	if c.indexReady() {
+		project, found, err := c.index.project(clusterID, projectID)
+		if err != nil {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return namespaceDefaults{}, fmt.Errorf("failed to get project %s from index: %w", projectID, err)
+		}
+		// A project created moments ago may not have reached the informers yet
+		if found {
+			metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProjectDefaults).Inc()
+			return c.namespaceDefaultsOf(project)
+		}
+	}
+
+	cacheKey := clusterID + ":" + projectID
+
+	// Check cache first
+	c.defaultsMu.RLock()
+	if entry, ok := c.defaultsCache[cacheKey]; ok && time.Now().Before(entry.expiresAt) {
+		c.defaultsMu.RUnlock()
+		metrics.CacheHitsTotal.WithLabelValues(metrics.CacheTypeProjectDefaults).Inc()
+		return entry, nil
+	}
+	c.defaultsMu.RUnlock()
+
+	// Cache miss - query API, sharing the call with concurrent misses
+	metrics.CacheMissesTotal.WithLabelValues(metrics.CacheTypeProjectDefaults).Inc()
+
+	// The defaults are returned by the flight itself: the cache may be
+	// cleared before the callers get to read it
+	defaults, shared, err := c.defaultsFlights.do(ctx, cacheKey, func(ctx context.Context) (namespaceDefaults, error) {
+		return c.fetchNamespaceDefaults(ctx, clusterID, projectID)
+	})
+	if shared {
+		metrics.CoalescedRequestsTotal.WithLabelValues(metrics.CacheTypeProjectDefaults).Inc()
+	}
+	if err != nil {
+		return namespaceDefaults{}, err
+	}
+	return defaults, nil
+}
+
+// fetchNamespaceDefaults reads a project with retries and caches and returns
+// its namespace defaults
+func (c *Client) fetchNamespaceDefaults(ctx context.Context, clusterID, projectID string) (namespaceDefaults, error) {
+	var project *unstructured.Unstructured
+	var err error
+	backoff := initialBackoff
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		project, err = c.dynamicClient.Resource(projectGVR).Namespace(clusterID).Get(ctx, projectID, metav1.GetOptions{})
+		if err == nil {
+			break
+		}
+
+		if errors.IsNotFound(err) {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeNotFound).Inc()
+			return namespaceDefaults{}, notFound(fmt.Errorf("project %s not found in cluster %s", projectID, clusterID))
+		}
+
+		if !isRetryableError(err) || attempt == maxRetries {
+			metrics.ProjectLookupErrorsTotal.WithLabelValues(metrics.ErrorTypeAPI).Inc()
+			return namespaceDefaults{}, fmt.Errorf("failed to get project %s in cluster %s: %w", projectID, clusterID, err)
+		}
+
+		c.logger.Debug("Retrying project defaults lookup",
+			slog.String("cluster_id", clusterID),
+			slog.String("project_id", projectID),
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return namespaceDefaults{}, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	defaults, err := c.namespaceDefaultsOf(project)
+	if err != nil {
+		return namespaceDefaults{}, err
+	}
+	defaults.expiresAt = time.Now().Add(c.cacheTTL)
+
+	c.defaultsMu.Lock()
+	c.defaultsCache[clusterID+":"+projectID] = defaults
+	c.defaultsMu.Unlock()
+
+	return defaults, nil
+}
+
+// namespaceDefaultsOf reads the namespace defaults from a project's spec and annotations
+func (c *Client) namespaceDefaultsOf(project *unstructured.Unstructured) (namespaceDefaults, error) {
+	defaults := namespaceDefaults{
+		labels:      c.namespaceMetadataOf(project, namespaceLabelsAnnotation, namespaceLabelPrefix, true),
+		annotations: c.namespaceMetadataOf(project, namespaceAnnotationsAnnotation, namespaceAnnotationPrefix, false),
+	}
+
+	for field, value := range map[string]*string{
+		"namespaceDefaultResourceQuota": &defaults.resourceQuota,
+		"containerDefaultResourceLimit": &defaults.containerLimit,
+	} {
+		spec, found, err := unstructured.NestedMap(project.Object, "spec", field)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid spec.%s in project %s: %w", field, project.GetName(), err)
+		}
+		if !found || len(spec) == 0 {
+			continue
+		}
+
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return defaults, fmt.Errorf("failed to marshal spec.%s of project %s: %w", field, project.GetName(), err)
+		}
+		*value = string(data)
+	}
+	return defaults, nil
+}
+
+// namespaceMetadataOf collects the namespace labels or annotations set on a
+// project, first from the JSON map in mapKey and then from annotations with
+// the given prefix. Entries the API server would reject are logged and skipped,
+// so that a typo on a project can't block namespace creation.
+func (c *Client) namespaceMetadataOf(project *unstructured.Unstructured, mapKey, prefix string, labels bool) map[string]string {
+	entries := make(map[string]string)
+	projectAnnotations := project.GetAnnotations()
+
+	if data, ok := projectAnnotations[mapKey]; ok {
+		if err := json.Unmarshal([]byte(data), &entries); err != nil {
+			c.logger.Warn("Ignoring invalid namespace metadata on project",
+				slog.String("project_id", project.GetName()),
+				slog.String("annotation", mapKey),
+				slog.String("error", err.Error()),
+			)
+			entries = make(map[string]string)
+		}
+	}
+	for key, value := range projectAnnotations {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			entries[name] = value
+		}
+	}
+
+	for key, value := range entries {
+		problems := validation.IsQualifiedName(key)
+		if labels {
+			problems = append(problems, validation.IsValidLabelValue(value)...)
+		}
+		if len(problems) > 0 {
+			c.logger.Warn("Ignoring invalid namespace metadata on project",
+				slog.String("project_id", project.GetName()),
+				slog.String("key", key),
+				slog.String("value", value),
+				slog.String("error", strings.Join(problems, "; ")),
+			)
+			delete(entries, key)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries
+}
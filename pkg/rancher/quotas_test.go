@@ -0,0 +1,112 @@
+package rancher
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newTestProjectWithDefaults returns a project with a namespace default
+// resource quota and container default resource limit
+func newTestProjectWithDefaults(name, namespace, displayName string) *unstructured.Unstructured {
+	project := newTestProject(name, namespace, displayName)
+	spec := project.Object["spec"].(map[string]any)
+	spec["namespaceDefaultResourceQuota"] = map[string]any{
+		"limit": map[string]any{"limitsCpu": "500m"},
+	}
+	spec["containerDefaultResourceLimit"] = map[string]any{
+		"limitsMemory": "256Mi",
+	}
+	return project
+}
+
+func TestGetNamespaceDefaults(t *testing.T) {
+	client, dynamicClient := newTestWorkspaceClient(testClientConfig(),
+		newTestProjectWithDefaults("p-abc12", "c-m-abc123", "payments"),
+		newTestProject("p-def34", "c-m-abc123", "sandbox"),
+	)
+
+	for i := 0; i < 2; i++ {
+		resourceQuota, containerLimit, err := client.GetNamespaceDefaults(context.Background(), "c-m-abc123", "p-abc12")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resourceQuota != `{"limit":{"limitsCpu":"500m"}}` {
+			t.Errorf("unexpected resource quota %q", resourceQuota)
+		}
+		if containerLimit != `{"limitsMemory":"256Mi"}` {
+			t.Errorf("unexpected container limit %q", containerLimit)
+		}
+	}
+	if got := countActions(dynamicClient, "get", "projects"); got != 1 {
+		t.Errorf("expected namespace defaults to be cached after 1 API call, got %d", got)
+	}
+
+	resourceQuota, containerLimit, err := client.GetNamespaceDefaults(context.Background(), "c-m-abc123", "p-def34")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resourceQuota != "" || containerLimit != "" {
+		t.Errorf("expected no defaults for a project without quotas, got %q and %q", resourceQuota, containerLimit)
+	}
+
+	if _, _, err := client.GetNamespaceDefaults(context.Background(), "c-m-abc123", "p-missing"); !isNotFound(err) {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+}
+
+func TestGetNamespaceDefaults_FromIndex(t *testing.T) {
+	client, dynamicClient := newTestIndexedClient(t, newTestProjectWithDefaults("p-abc12", "c-m-abc123", "payments"))
+
+	resourceQuota, containerLimit, err := client.GetNamespaceDefaults(context.Background(), "c-m-abc123", "p-abc12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resourceQuota == "" || containerLimit == "" {
+		t.Errorf("expected defaults from the index, got %q and %q", resourceQuota, containerLimit)
+	}
+	if got := countActions(dynamicClient, "get", "projects"); got != 0 {
+		t.Errorf("expected no API calls with a synced index, got %d", got)
+	}
+}
+
+func TestGetNamespaceMetadata(t *testing.T) {
+	project := newTestProject("p-abc12", "c-m-abc123", "payments")
+	project.SetAnnotations(map[string]string{
+		"fencemaster.io/namespace-label.cost-center":    "cc-1234",
+		"fencemaster.io/namespace-label.team":           "not a valid label value",
+		"fencemaster.io/namespace-labels":               `{"pod-security.kubernetes.io/enforce":"baseline","team":"payments"}`,
+		"fencemaster.io/namespace-annotation.owner":     "payments@example.com",
+		"fencemaster.io/namespace-annotations":          `not json`,
+		"field.cattle.io/creatorId":                     "u-abc12",
+		"fencemaster.io/namespace-label.bad/key/format": "x",
+	})
+	client, _ := newTestWorkspaceClient(testClientConfig(), project)
+
+	labels, annotations, err := client.GetNamespaceMetadata(context.Background(), "c-m-abc123", "p-abc12")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The invalid prefixed team label replaces the map's, and is then dropped
+	expectedLabels := map[string]string{
+		"cost-center":                        "cc-1234",
+		"pod-security.kubernetes.io/enforce": "baseline",
+	}
+	if !maps.Equal(labels, expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, labels)
+	}
+	expectedAnnotations := map[string]string{"owner": "payments@example.com"}
+	if !maps.Equal(annotations, expectedAnnotations) {
+		t.Errorf("expected annotations %v, got %v", expectedAnnotations, annotations)
+	}
+
+	// Callers get copies of the cached maps
+	labels["cost-center"] = "changed"
+	labels, _, _ = client.GetNamespaceMetadata(context.Background(), "c-m-abc123", "p-abc12")
+	if labels["cost-center"] != "cc-1234" {
+		t.Errorf("expected cached labels to be unchanged, got %v", labels)
+	}
+}
@@ -0,0 +1,94 @@
+package rancher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DefaultFleetWorkspace is the Fleet workspace Rancher provisions clusters into
+	DefaultFleetWorkspace = "fleet-default"
+	// AllWorkspaces looks clusters up in every namespace
+	AllWorkspaces = "*"
+)
+
+// workspaces is the set of Fleet workspaces (namespaces) clusters are looked up in
+type workspaces struct {
+	// names is nil when clusters are looked up in all namespaces
+	names []string
+}
+
+// newWorkspaces builds the workspace set from configuration. An empty list
+// means fleet-default, and "*" anywhere in the list means all namespaces.
+func newWorkspaces(names []string) workspaces {
+	var w workspaces
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == AllWorkspaces {
+			return workspaces{}
+		}
+		if name != "" && !slices.Contains(w.names, name) {
+			w.names = append(w.names, name)
+		}
+	}
+	if len(w.names) == 0 {
+		w.names = []string{DefaultFleetWorkspace}
+	}
+	return w
+}
+
+// all reports whether clusters are looked up in every namespace
+func (w workspaces) all() bool {
+	return w.names == nil
+}
+
+// namespace returns the namespace to scope list and watch calls to: the
+// workspace itself when there is only one, otherwise all namespaces
+func (w workspaces) namespace() string {
+	if len(w.names) == 1 {
+		return w.names[0]
+	}
+	return ""
+}
+
+// contains reports whether namespace is one of the configured workspaces
+func (w workspaces) contains(namespace string) bool {
+	return w.all() || slices.Contains(w.names, namespace)
+}
+
+// String returns the workspaces as shown in logs
+func (w workspaces) String() string {
+	if w.all() {
+		return AllWorkspaces
+	}
+	return strings.Join(w.names, ",")
+}
+
+// selectCluster picks the single cluster named clusterName from the configured
+// workspaces. It returns nil when there is none and ErrAmbiguousCluster when
+// the name exists in more than one workspace.
+func (w workspaces) selectCluster(clusterName string, candidates []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	var matches []*unstructured.Unstructured
+	for _, cluster := range candidates {
+		if cluster.GetName() == clusterName && w.contains(cluster.GetNamespace()) {
+			matches = append(matches, cluster)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	namespaces := make([]string, 0, len(matches))
+	for _, cluster := range matches {
+		namespaces = append(namespaces, cluster.GetNamespace())
+	}
+	slices.Sort(namespaces)
+	return nil, fmt.Errorf("%w: %s exists in fleet workspaces %s", ErrAmbiguousCluster, clusterName, strings.Join(namespaces, ", "))
+}
@@ -0,0 +1,211 @@
+package rancher
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestCluster(name, namespace, clusterID string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "provisioning.cattle.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"status": map[string]any{
+				"clusterName": clusterID,
+			},
+		},
+	}
+}
+
+func newTestWorkspaceClient(cfg ClientConfig, objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		clusterGVR:                    "ClusterList",
+		projectGVR:                    "ProjectList",
+		managementClusterGVR:          "ClusterList",
+		projectRoleTemplateBindingGVR: "ProjectRoleTemplateBindingList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, objects...)
+	return NewClient(dynamicClient, newTestLogger(), cfg), dynamicClient
+}
+
+func TestNewWorkspaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []string
+		wantAll   bool
+		wantNames []string
+		wantNS    string
+	}{
+		{name: "empty defaults to fleet-default", input: nil, wantNames: []string{"fleet-default"}, wantNS: "fleet-default"},
+		{name: "single workspace", input: []string{"fleet-custom"}, wantNames: []string{"fleet-custom"}, wantNS: "fleet-custom"},
+		{name: "multiple workspaces", input: []string{"fleet-default", " fleet-custom ", "fleet-default", ""}, wantNames: []string{"fleet-default", "fleet-custom"}, wantNS: ""},
+		{name: "wildcard", input: []string{"fleet-default", "*"}, wantAll: true, wantNS: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWorkspaces(tt.input)
+			if w.all() != tt.wantAll {
+				t.Errorf("all() = %v, want %v", w.all(), tt.wantAll)
+			}
+			if !slices.Equal(w.names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", w.names, tt.wantNames)
+			}
+			if w.namespace() != tt.wantNS {
+				t.Errorf("namespace() = %q, want %q", w.namespace(), tt.wantNS)
+			}
+		})
+	}
+}
+
+func TestSelectCluster(t *testing.T) {
+	w := newWorkspaces([]string{"fleet-default", "fleet-custom"})
+	candidates := []*unstructured.Unstructured{
+		newTestCluster("a", "fleet-default", "c-m-a"),
+		newTestCluster("b", "fleet-custom", "c-m-b1"),
+		newTestCluster("b", "fleet-default", "c-m-b2"),
+		newTestCluster("c", "other", "c-m-c"),
+	}
+
+	cluster, err := w.selectCluster("a", candidates)
+	if err != nil || cluster == nil || clusterIDOf(cluster) != "c-m-a" {
+		t.Errorf("expected cluster a, got %v (err: %v)", cluster, err)
+	}
+
+	if cluster, err := w.selectCluster("c", candidates); err != nil || cluster != nil {
+		t.Errorf("expected cluster outside workspaces to be ignored, got %v (err: %v)", cluster, err)
+	}
+
+	_, err = w.selectCluster("b", candidates)
+	if !errors.Is(err, ErrAmbiguousCluster) {
+		t.Fatalf("expected ErrAmbiguousCluster, got %v", err)
+	}
+	if want := "ambiguous cluster name: b exists in fleet workspaces fleet-custom, fleet-default"; err.Error() != want {
+		t.Errorf("expected error %q, got %q", want, err.Error())
+	}
+}
+
+func TestGetClusterID_CustomWorkspace(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, FleetWorkspaces: []string{"fleet-custom"}},
+		newTestCluster("test-cluster", "fleet-custom", "c-m-abc123"),
+	)
+
+	clusterID, err := client.GetClusterID(context.Background(), "test-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-m-abc123" {
+		t.Errorf("expected cluster ID 'c-m-abc123', got '%s'", clusterID)
+	}
+}
+
+func TestGetClusterID_MultipleWorkspaces(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, FleetWorkspaces: []string{"fleet-default", "fleet-custom"}},
+		newTestCluster("cluster-a", "fleet-default", "c-m-a"),
+		newTestCluster("cluster-b", "fleet-custom", "c-m-b"),
+		newTestCluster("shared", "fleet-default", "c-m-s1"),
+		newTestCluster("shared", "fleet-custom", "c-m-s2"),
+		newTestCluster("elsewhere", "other", "c-m-e"),
+	)
+
+	for name, want := range map[string]string{"cluster-a": "c-m-a", "cluster-b": "c-m-b"} {
+		clusterID, err := client.GetClusterID(context.Background(), name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+		if clusterID != want {
+			t.Errorf("expected cluster ID %q for %s, got %q", want, name, clusterID)
+		}
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "shared"); !errors.Is(err, ErrAmbiguousCluster) {
+		t.Errorf("expected ErrAmbiguousCluster, got %v", err)
+	}
+	if _, err := client.GetClusterID(context.Background(), "elsewhere"); err == nil || errors.Is(err, ErrAmbiguousCluster) {
+		t.Errorf("expected not-found error for cluster outside workspaces, got %v", err)
+	}
+}
+
+func TestGetClusterID_AllWorkspaces(t *testing.T) {
+	client, _ := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, FleetWorkspaces: []string{AllWorkspaces}},
+		newTestCluster("cluster-a", "team-a", "c-m-a"),
+		newTestCluster("shared", "team-a", "c-m-s1"),
+		newTestCluster("shared", "team-b", "c-m-s2"),
+	)
+
+	clusterID, err := client.GetClusterID(context.Background(), "cluster-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-m-a" {
+		t.Errorf("expected cluster ID 'c-m-a', got '%s'", clusterID)
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "shared"); !errors.Is(err, ErrAmbiguousCluster) {
+		t.Errorf("expected ErrAmbiguousCluster, got %v", err)
+	}
+
+	// Ambiguity is a configuration problem, not a missing cluster
+	if len(client.negativeCache) != 0 {
+		t.Errorf("expected ambiguous lookups not to be negatively cached, got %d entries", len(client.negativeCache))
+	}
+}
+
+func TestGetClusterID_FromIndexAcrossWorkspaces(t *testing.T) {
+	client, _ := newTestIndexedClientWithConfig(t,
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, FleetWorkspaces: []string{"fleet-default", "fleet-custom"}},
+		newTestCluster("cluster-b", "fleet-custom", "c-m-b"),
+		newTestCluster("shared", "fleet-default", "c-m-s1"),
+		newTestCluster("shared", "fleet-custom", "c-m-s2"),
+		newTestCluster("elsewhere", "other", "c-m-e"),
+	)
+
+	clusterID, err := client.GetClusterID(context.Background(), "cluster-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clusterID != "c-m-b" {
+		t.Errorf("expected cluster ID 'c-m-b', got '%s'", clusterID)
+	}
+
+	if _, err := client.GetClusterID(context.Background(), "shared"); !errors.Is(err, ErrAmbiguousCluster) {
+		t.Errorf("expected ErrAmbiguousCluster, got %v", err)
+	}
+	if _, err := client.GetClusterID(context.Background(), "elsewhere"); err == nil {
+		t.Error("expected error for cluster outside workspaces")
+	}
+}
+
+func TestHealthCheck_ChecksEveryWorkspace(t *testing.T) {
+	client, dynamicClient := newTestWorkspaceClient(
+		ClientConfig{CacheTTL: testClientConfig().CacheTTL, FleetWorkspaces: []string{"fleet-default", "fleet-custom"}},
+	)
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var namespaces []string
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "clusters" {
+			namespaces = append(namespaces, action.GetNamespace())
+		}
+	}
+	if !slices.Equal(namespaces, []string{"fleet-default", "fleet-custom"}) {
+		t.Errorf("expected cluster health checks in both workspaces, got %v", namespaces)
+	}
+}
@@ -0,0 +1,97 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubeconfigSecretKey is the key of the kubeconfig in Rancher's cluster kubeconfig Secrets
+const kubeconfigSecretKey = "value"
+
+// Connector returns a client for a downstream cluster
+type Connector interface {
+	Connect(ctx context.Context, cluster rancher.Cluster) (kubernetes.Interface, error)
+}
+
+// ProxyConnector reaches downstream clusters through Rancher's cluster proxy
+// at {url}/k8s/clusters/{cluster-id}, authenticating with a Rancher API token
+type ProxyConnector struct {
+	url       string
+	tokenFile string
+	caFile    string
+}
+
+// NewProxyConnector creates a connector for the Rancher server at url. The
+// token is read from tokenFile on every request, so it can be rotated; caFile
+// verifies the Rancher server certificate (default: system roots).
+func NewProxyConnector(url, tokenFile, caFile string) *ProxyConnector {
+	return &ProxyConnector{
+		url:       strings.TrimSuffix(url, "/"),
+		tokenFile: tokenFile,
+		caFile:    caFile,
+	}
+}
+
+// Connect returns a client for a cluster through the Rancher cluster proxy
+func (c *ProxyConnector) Connect(ctx context.Context, cluster rancher.Cluster) (kubernetes.Interface, error) {
+	return kubernetes.NewForConfig(&rest.Config{
+		Host:            c.url + "/k8s/clusters/" + cluster.ID,
+		BearerTokenFile: c.tokenFile,
+		TLSClientConfig: rest.TLSClientConfig{CAFile: c.caFile},
+	})
+}
+
+// SecretConnector reaches provisioned clusters with the kubeconfig Rancher
+// stores for each of them in a {cluster-name}-kubeconfig Secret in its Fleet
+// workspace. Imported and other clusters without a provisioning cluster have
+// no such Secret.
+type SecretConnector struct {
+	client kubernetes.Interface
+}
+
+// NewSecretConnector creates a connector reading kubeconfig Secrets through client
+func NewSecretConnector(client kubernetes.Interface) *SecretConnector {
+	return &SecretConnector{client: client}
+}
+
+// Connect returns a client for a cluster from its kubeconfig Secret
+func (c *SecretConnector) Connect(ctx context.Context, cluster rancher.Cluster) (kubernetes.Interface, error) {
+	if cluster.Workspace == "" {
+		return nil, fmt.Errorf("cluster %s has no provisioning cluster and so no kubeconfig secret; use the Rancher cluster proxy", cluster.Name)
+	}
+
+	name := cluster.Name + "-kubeconfig"
+	secret, err := c.client.CoreV1().Secrets(cluster.Workspace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", cluster.Workspace, name, err)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data[kubeconfigSecretKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig in secret %s/%s: %w", cluster.Workspace, name, err)
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// StaticConnector connects to one cluster with a given client, e.g. from a
+// kubeconfig file for that cluster
+type StaticConnector struct {
+	client kubernetes.Interface
+}
+
+// NewStaticConnector creates a connector returning client for any cluster
+func NewStaticConnector(client kubernetes.Interface) *StaticConnector {
+	return &StaticConnector{client: client}
+}
+
+// Connect returns the connector's client
+func (c *StaticConnector) Connect(ctx context.Context, cluster rancher.Cluster) (kubernetes.Interface, error) {
+	return c.client, nil
+}
@@ -0,0 +1,74 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: prod
+    cluster:
+      server: https://prod.example.com:6443
+contexts:
+  - name: prod
+    context:
+      cluster: prod
+      user: prod
+current-context: prod
+users:
+  - name: prod
+    user:
+      token: secret-token
+`
+
+func TestSecretConnector(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-kubeconfig", Namespace: "fleet-default"},
+		Data:       map[string][]byte{"value": []byte(testKubeconfig)},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken-kubeconfig", Namespace: "fleet-default"},
+		Data:       map[string][]byte{"value": []byte("not a kubeconfig")},
+	})
+	connector := NewSecretConnector(client)
+
+	if _, err := connector.Connect(context.Background(), rancher.Cluster{Name: "prod", Workspace: "fleet-default", ID: "c-m-abc123"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, cluster := range []rancher.Cluster{
+		{Name: "dev", Workspace: "fleet-default", ID: "c-m-def456"},
+		{Name: "broken", Workspace: "fleet-default", ID: "c-m-ghi789"},
+		{Name: "legacy", ID: "c-xyz12"},
+	} {
+		if _, err := connector.Connect(context.Background(), cluster); err == nil {
+			t.Errorf("expected error for cluster %s", cluster.Name)
+		}
+	}
+}
+
+func TestProxyConnector(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token-abc12:secret"), 0o600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+
+	connector := NewProxyConnector("https://rancher.example.com/", tokenFile, "")
+	client, err := connector.Connect(context.Background(), rancher.Cluster{Name: "prod", ID: "c-m-abc123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	url := client.CoreV1().RESTClient().Get().AbsPath("/api/v1/namespaces").URL().String()
+	if url != "https://rancher.example.com/k8s/clusters/c-m-abc123/api/v1/namespaces" {
+		t.Errorf("unexpected URL %s", url)
+	}
+}
@@ -0,0 +1,49 @@
+package reconciler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Leader election timings, as used by Kubernetes controllers
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// StartLeader runs Start on one replica at a time, holding a Lease named
+// leaseName in namespace. Replicas that aren't the leader wait to take over.
+// It returns when ctx is cancelled.
+func (r *Reconciler) StartLeader(ctx context.Context, client kubernetes.Interface, namespace, leaseName, identity string, interval time.Duration) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: leaseName},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					r.logger.Info("Became reconciler leader", slog.String("identity", identity))
+					r.Start(ctx, interval)
+				},
+				OnStoppedLeading: func() {
+					r.logger.Info("Stopped being reconciler leader", slog.String("identity", identity))
+				},
+			},
+		})
+	}
+}
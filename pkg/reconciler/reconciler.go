@@ -0,0 +1,347 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	"github.com/rvbsalgado/fencemaster/pkg/webhook"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultUser is the username reviews are made as, which project access rules can allow
+	DefaultUser = "system:fencemaster:reconciler"
+	// listPageSize is the number of namespaces listed per request
+	listPageSize = 500
+	// fieldManager records the reconciler's changes in managedFields
+	fieldManager = "fencemaster"
+)
+
+// Mode decides what the reconciler does with namespaces that need changes
+type Mode string
+
+const (
+	// ModePatch patches namespaces
+	ModePatch Mode = "patch"
+	// ModeReport only logs and counts the changes
+	ModeReport Mode = "report"
+)
+
+// ParseMode parses a reconcile mode
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.TrimSpace(s)); mode {
+	case ModePatch, ModeReport:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid reconcile mode %q (must be patch or report)", s)
+	}
+}
+
+// Action is what happened to a reviewed namespace
+type Action string
+
+const (
+	// ActionInSync means the namespace needs no changes
+	ActionInSync Action = "in_sync"
+	// ActionPatched means the namespace was patched
+	ActionPatched Action = "patched"
+	// ActionDrift means the namespace needs changes that weren't applied
+	ActionDrift Action = "drift"
+	// ActionSkipped means the webhook would admit the namespace without assigning it, e.g. its project doesn't exist
+	ActionSkipped Action = "skipped"
+	// ActionDenied means the webhook would reject the namespace
+	ActionDenied Action = "denied"
+	// ActionFailed means the namespace couldn't be reviewed or patched
+	ActionFailed Action = "failed"
+)
+
+// Result is the outcome of reconciling one namespace
+type Result struct {
+	Cluster   string
+	Namespace string
+	// Current is the namespace's project annotation
+	Current string
+	// Desired is the project annotation fencemaster sets ("" when it leaves the namespace as is)
+	Desired string
+	Action  Action
+	// Message is why a namespace was skipped, denied or failed, or the project
+	// it would be assigned to once created
+	Message string
+}
+
+// Reviewer decides what the webhook would do with an existing namespace. In
+// report mode the review must have no side effects, such as creating projects.
+type Reviewer interface {
+	ReviewNamespace(ctx context.Context, clusterName, clusterID string, namespace *corev1.Namespace, user authenticationv1.UserInfo, report bool) (webhook.NamespaceReview, error)
+}
+
+// ClusterLister lists the downstream clusters
+type ClusterLister interface {
+	ListClusters(ctx context.Context) ([]rancher.Cluster, error)
+}
+
+// Config contains configuration options for the reconciler
+type Config struct {
+	// Mode is what to do with namespaces that need changes (default: report)
+	Mode Mode
+	// Clusters limits reconciliation to these cluster names or IDs (default: all)
+	Clusters []string
+	// ProjectAnnotation is the namespace annotation holding the project
+	ProjectAnnotation string
+	// User is who reviews are made as, for the project access policy (default: DefaultUser)
+	User authenticationv1.UserInfo
+}
+
+// Reconciler assigns namespaces that exist in downstream clusters without
+// having gone through the webhook, e.g. created before fencemaster was
+// installed or while it was down, using the same logic as the webhook
+type Reconciler struct {
+	reviewer          Reviewer
+	clusters          ClusterLister
+	connector         Connector
+	logger            *slog.Logger
+	mode              Mode
+	only              []string
+	projectAnnotation string
+	user              authenticationv1.UserInfo
+}
+
+func New(reviewer Reviewer, clusters ClusterLister, connector Connector, logger *slog.Logger, cfg Config) *Reconciler {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeReport
+	}
+	if cfg.User.Username == "" {
+		cfg.User.Username = DefaultUser
+	}
+
+	return &Reconciler{
+		reviewer:          reviewer,
+		clusters:          clusters,
+		connector:         connector,
+		logger:            logger,
+		mode:              cfg.Mode,
+		only:              cfg.Clusters,
+		projectAnnotation: cfg.ProjectAnnotation,
+		user:              cfg.User,
+	}
+}
+
+// Start reconciles all clusters now and then every interval, until ctx is cancelled
+func (r *Reconciler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.Run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run reconciles every cluster once. Failures are logged and counted, and
+// don't stop the other clusters from being reconciled.
+func (r *Reconciler) Run(ctx context.Context) {
+	start := time.Now()
+
+	clusters, err := r.Clusters(ctx)
+	if err != nil {
+		metrics.ReconcileRunsTotal.WithLabelValues(metrics.ResultError).Inc()
+		r.logger.Error("Failed to list clusters to reconcile", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, cluster := range clusters {
+		if ctx.Err() != nil {
+			return
+		}
+
+		results, err := r.ReconcileCluster(ctx, cluster)
+		if err != nil {
+			metrics.ReconcileRunsTotal.WithLabelValues(metrics.ResultError).Inc()
+			r.logger.Error("Failed to reconcile cluster",
+				slog.String("cluster", cluster.Name),
+				slog.String("cluster_id", cluster.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		metrics.ReconcileRunsTotal.WithLabelValues(metrics.ResultSuccess).Inc()
+
+		counts := Count(results)
+		r.logger.Info("Reconciled cluster",
+			slog.String("cluster", cluster.Name),
+			slog.String("cluster_id", cluster.ID),
+			slog.String("mode", string(r.mode)),
+			slog.Int("namespaces", len(results)),
+			slog.Int("in_sync", counts[ActionInSync]),
+			slog.Int("patched", counts[ActionPatched]),
+			slog.Int("drift", counts[ActionDrift]),
+			slog.Int("skipped", counts[ActionSkipped]),
+			slog.Int("denied", counts[ActionDenied]),
+			slog.Int("failed", counts[ActionFailed]),
+		)
+	}
+
+	r.logger.Debug("Reconciliation finished",
+		slog.Int("clusters", len(clusters)),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// Clusters returns the clusters to reconcile
+func (r *Reconciler) Clusters(ctx context.Context) ([]rancher.Cluster, error) {
+	clusters, err := r.clusters.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.only) == 0 {
+		return clusters, nil
+	}
+	return slices.DeleteFunc(clusters, func(cluster rancher.Cluster) bool {
+		return !slices.Contains(r.only, cluster.Name) && !slices.Contains(r.only, cluster.ID)
+	}), nil
+}
+
+// ReconcileCluster reviews every namespace of a cluster and, in patch mode,
+// patches those that need changes. It returns an error only when the
+// namespaces can't be listed; failures of single namespaces are in the results.
+func (r *Reconciler) ReconcileCluster(ctx context.Context, cluster rancher.Cluster) ([]Result, error) {
+	client, err := r.connector.Connect(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to cluster %s: %w", cluster.Name, err)
+	}
+
+	var results []Result
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := client.CoreV1().Namespaces().List(ctx, opts)
+		if err != nil {
+			return results, fmt.Errorf("failed to list namespaces in cluster %s: %w", cluster.Name, err)
+		}
+
+		for i := range list.Items {
+			namespace := &list.Items[i]
+			if namespace.Status.Phase == corev1.NamespaceTerminating {
+				continue
+			}
+			result := r.reconcileNamespace(ctx, client, cluster, namespace)
+			metrics.ReconciledNamespacesTotal.WithLabelValues(string(result.Action)).Inc()
+			results = append(results, result)
+		}
+
+		if list.Continue == "" {
+			return results, nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// reconcileNamespace reviews a namespace and patches it in patch mode
+func (r *Reconciler) reconcileNamespace(ctx context.Context, client kubernetes.Interface, cluster rancher.Cluster, namespace *corev1.Namespace) Result {
+	result := Result{
+		Cluster:   cluster.Name,
+		Namespace: namespace.Name,
+		Current:   namespace.Annotations[r.projectAnnotation],
+	}
+
+	review, err := r.reviewer.ReviewNamespace(ctx, cluster.Name, cluster.ID, namespace, r.user, r.mode != ModePatch)
+	switch {
+	case err != nil:
+		result.Action, result.Message = ActionFailed, err.Error()
+		return result
+	case review.Denied:
+		result.Action, result.Message = ActionDenied, review.Message
+		return result
+	case review.CreatesProject != "":
+		result.Action = ActionDrift
+		result.Message = fmt.Sprintf("would create project %s", review.CreatesProject)
+		r.logger.Info("Namespace needs a project that doesn't exist yet",
+			slog.String("cluster", cluster.Name),
+			slog.String("namespace", namespace.Name),
+			slog.String("current", result.Current),
+			slog.String("project", review.CreatesProject),
+		)
+		return result
+	case review.Patch == nil && len(review.Warnings) > 0:
+		result.Action, result.Message = ActionSkipped, strings.Join(review.Warnings, "; ")
+		return result
+	case review.Patch == nil:
+		result.Action = ActionInSync
+		return result
+	}
+
+	result.Desired = review.Annotation
+	if r.mode != ModePatch {
+		result.Action = ActionDrift
+		r.logger.Info("Namespace needs changes",
+			slog.String("cluster", cluster.Name),
+			slog.String("namespace", namespace.Name),
+			slog.String("current", result.Current),
+			slog.String("desired", result.Desired),
+		)
+		return result
+	}
+
+	if err := patchNamespace(ctx, client, namespace, review.Patch); err != nil {
+		result.Action, result.Message = ActionFailed, err.Error()
+		r.logger.Error("Failed to patch namespace",
+			slog.String("cluster", cluster.Name),
+			slog.String("namespace", namespace.Name),
+			slog.String("error", err.Error()),
+		)
+		return result
+	}
+
+	result.Action = ActionPatched
+	r.logger.Info("Patched namespace",
+		slog.String("cluster", cluster.Name),
+		slog.String("namespace", namespace.Name),
+		slog.String("previous", result.Current),
+		slog.String("annotation", result.Desired),
+	)
+	return result
+}
+
+// patchNamespace applies a webhook patch to a namespace, provided it hasn't
+// changed since it was reviewed. A namespace that changed in the meantime
+// went through the webhook, or will be reviewed again on the next run.
+func patchNamespace(ctx context.Context, client kubernetes.Interface, namespace *corev1.Namespace, patch []byte) error {
+	var ops []json.RawMessage
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return fmt.Errorf("failed to unmarshal patch: %w", err)
+	}
+
+	test, err := json.Marshal(map[string]any{
+		"op":    "test",
+		"path":  "/metadata/resourceVersion",
+		"value": namespace.ResourceVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+	patch, err = json.Marshal(append([]json.RawMessage{test}, ops...))
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	_, err = client.CoreV1().Namespaces().Patch(ctx, namespace.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		return fmt.Errorf("failed to patch namespace %s: %w", namespace.Name, err)
+	}
+	return nil
+}
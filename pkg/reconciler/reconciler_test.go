@@ -0,0 +1,304 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	"github.com/rvbsalgado/fencemaster/pkg/webhook"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testAnnotation = "field.cattle.io/projectId"
+
+// mockReviewer assigns namespaces labeled project=platform to c-m-abc123:p-xyz789
+type mockReviewer struct {
+	users   []string
+	reports []bool
+}
+
+func (m *mockReviewer) ReviewNamespace(ctx context.Context, clusterName, clusterID string, namespace *corev1.Namespace, user authenticationv1.UserInfo, report bool) (webhook.NamespaceReview, error) {
+	m.users = append(m.users, user.Username)
+	m.reports = append(m.reports, report)
+
+	switch namespace.Labels["project"] {
+	case "":
+		return webhook.NamespaceReview{}, nil
+	case "missing":
+		return webhook.NamespaceReview{Warnings: []string{"project 'missing' not found"}}, nil
+	case "protected":
+		return webhook.NamespaceReview{Denied: true, Message: "project 'protected' is protected"}, nil
+	case "broken":
+		return webhook.NamespaceReview{}, errors.New("failed to marshal namespace")
+	}
+
+	annotation := clusterID + ":p-xyz789"
+	if namespace.Annotations[testAnnotation] == annotation {
+		return webhook.NamespaceReview{}, nil
+	}
+	return webhook.NamespaceReview{
+		Patch:      []byte(`[{"op":"add","path":"/metadata/annotations","value":{}},{"op":"add","path":"/metadata/annotations/field.cattle.io~1projectId","value":"` + annotation + `"}]`),
+		Annotation: annotation,
+	}, nil
+}
+
+type mockClusterLister struct {
+	clusters []rancher.Cluster
+	err      error
+}
+
+func (m *mockClusterLister) ListClusters(ctx context.Context) ([]rancher.Cluster, error) {
+	return m.clusters, m.err
+}
+
+// mockConnector connects to fake clientsets by cluster ID
+type mockConnector struct {
+	clients map[string]kubernetes.Interface
+}
+
+func (m *mockConnector) Connect(ctx context.Context, cluster rancher.Cluster) (kubernetes.Interface, error) {
+	client, ok := m.clients[cluster.ID]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return client, nil
+}
+
+func newTestNamespace(name, project string, annotations map[string]string) *corev1.Namespace {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			ResourceVersion: "1",
+			Annotations:     annotations,
+		},
+	}
+	if project != "" {
+		namespace.Labels = map[string]string{"project": project}
+	}
+	return namespace
+}
+
+func newTestReconciler(mode Mode, clients map[string]kubernetes.Interface, clusters ...rancher.Cluster) (*Reconciler, *mockReviewer) {
+	reviewer := &mockReviewer{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(reviewer, &mockClusterLister{clusters: clusters}, &mockConnector{clients: clients}, logger, Config{
+		Mode:              mode,
+		ProjectAnnotation: testAnnotation,
+	}), reviewer
+}
+
+func testObjects() []runtime.Object {
+	terminating := newTestNamespace("leaving", "platform", nil)
+	terminating.Status.Phase = corev1.NamespaceTerminating
+
+	return []runtime.Object{
+		newTestNamespace("unlabeled", "", nil),
+		newTestNamespace("assigned", "platform", map[string]string{testAnnotation: "c-m-abc123:p-xyz789"}),
+		newTestNamespace("unassigned", "platform", nil),
+		newTestNamespace("drifted", "platform", map[string]string{testAnnotation: "c-m-abc123:p-old00"}),
+		newTestNamespace("missing", "missing", nil),
+		newTestNamespace("protected", "protected", nil),
+		newTestNamespace("broken", "broken", nil),
+		terminating,
+	}
+}
+
+func TestReconcileCluster(t *testing.T) {
+	expected := map[string]Action{
+		"unlabeled":  ActionInSync,
+		"assigned":   ActionInSync,
+		"unassigned": ActionDrift,
+		"drifted":    ActionDrift,
+		"missing":    ActionSkipped,
+		"protected":  ActionDenied,
+		"broken":     ActionFailed,
+	}
+
+	for _, mode := range []Mode{ModeReport, ModePatch} {
+		t.Run(string(mode), func(t *testing.T) {
+			client := fake.NewSimpleClientset(testObjects()...)
+			cluster := rancher.Cluster{Name: "prod", Workspace: "fleet-default", ID: "c-m-abc123"}
+			r, reviewer := newTestReconciler(mode, map[string]kubernetes.Interface{"c-m-abc123": client}, cluster)
+
+			results, err := r.ReconcileCluster(context.Background(), cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(expected) {
+				t.Errorf("expected %d results, got %v", len(expected), results)
+			}
+
+			for _, result := range results {
+				want := expected[result.Namespace]
+				if mode == ModePatch && want == ActionDrift {
+					want = ActionPatched
+				}
+				if result.Action != want {
+					t.Errorf("%s: expected %s, got %s (%s)", result.Namespace, want, result.Action, result.Message)
+				}
+				if result.Cluster != "prod" {
+					t.Errorf("%s: expected cluster prod, got %s", result.Namespace, result.Cluster)
+				}
+			}
+
+			drifted := results[slices.IndexFunc(results, func(r Result) bool { return r.Namespace == "drifted" })]
+			if drifted.Current != "c-m-abc123:p-old00" || drifted.Desired != "c-m-abc123:p-xyz789" {
+				t.Errorf("unexpected drift %q -> %q", drifted.Current, drifted.Desired)
+			}
+
+			namespace, err := client.CoreV1().Namespaces().Get(context.Background(), "unassigned", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			annotation := namespace.Annotations[testAnnotation]
+			if mode == ModePatch && annotation != "c-m-abc123:p-xyz789" {
+				t.Errorf("expected namespace to be patched, got annotation %q", annotation)
+			}
+			if mode == ModeReport && annotation != "" {
+				t.Errorf("expected namespace not to be patched in report mode, got annotation %q", annotation)
+			}
+
+			if reviewer.users[0] != DefaultUser {
+				t.Errorf("expected reviews as %s, got %s", DefaultUser, reviewer.users[0])
+			}
+			if report := mode == ModeReport; slices.Contains(reviewer.reports, !report) {
+				t.Errorf("expected report=%v in every review, got %v", report, reviewer.reports)
+			}
+		})
+	}
+}
+
+func TestReconcileCluster_ChangedSinceReview(t *testing.T) {
+	client := fake.NewSimpleClientset(newTestNamespace("unassigned", "platform", nil))
+	cluster := rancher.Cluster{Name: "prod", ID: "c-m-abc123"}
+	r, _ := newTestReconciler(ModePatch, map[string]kubernetes.Interface{"c-m-abc123": client}, cluster)
+
+	// The namespace is reviewed at resource version 1, but is now at 2
+	namespace := newTestNamespace("unassigned", "platform", nil)
+	namespace.ResourceVersion = "2"
+	if _, err := client.CoreV1().Namespaces().Update(context.Background(), namespace, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stale := newTestNamespace("unassigned", "platform", nil)
+
+	result := r.reconcileNamespace(context.Background(), client, cluster, stale)
+	if result.Action != ActionFailed {
+		t.Errorf("expected the patch of a changed namespace to fail, got %s", result.Action)
+
+	}
+}
+
+func TestReconcileCluster_ReportNeverCreatesProjects(t *testing.T) {
+	gvrToListKind := map[schema.GroupVersionResource]string{
+		{Group: "provisioning.cattle.io", Version: "v1", Resource: "clusters"}: "ClusterList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "projects"}:   "ProjectList",
+		{Group: "management.cattle.io", Version: "v3", Resource: "clusters"}:   "ClusterList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind)
+	dynamicClient.PrependReactor("create", "projects", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		t.Errorf("unexpected project create in report mode: %v", action)
+		return true, nil, errors.New("projects can't be created in report mode")
+	})
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rancherClient := rancher.NewClient(dynamicClient, logger, rancher.ClientConfig{})
+	handler := webhook.NewHandler(rancherClient, logger, webhook.HandlerConfig{
+		ProjectLabel:      "project",
+		ProjectAnnotation: testAnnotation,
+		CreateProjects:    true,
+	})
+
+	client := fake.NewSimpleClientset(newTestNamespace("payments-api", "payments", nil))
+	cluster := rancher.Cluster{Name: "prod", ID: "c-m-abc123"}
+	r := New(handler, &mockClusterLister{clusters: []rancher.Cluster{cluster}}, &mockConnector{
+		clients: map[string]kubernetes.Interface{"c-m-abc123": client},
+	}, logger, Config{Mode: ModeReport, ProjectAnnotation: testAnnotation})
+
+	results, err := r.ReconcileCluster(context.Background(), cluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Action != ActionDrift || results[0].Message != "would create project payments" {
+		t.Fatalf("expected the missing project to be reported as drift, got %+v", results)
+	}
+
+	namespace, err := client.CoreV1().Namespaces().Get(context.Background(), "payments-api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if annotation := namespace.Annotations[testAnnotation]; annotation != "" {
+		t.Errorf("expected namespace not to be patched in report mode, got annotation %q", annotation)
+	}
+}
+
+func TestClusters(t *testing.T) {
+	clusters := []rancher.Cluster{
+		{Name: "prod", Workspace: "fleet-default", ID: "c-m-abc123"},
+		{Name: "dev", Workspace: "fleet-default", ID: "c-m-def456"},
+		{Name: "legacy", ID: "c-xyz12"},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r := New(&mockReviewer{}, &mockClusterLister{clusters: slices.Clone(clusters)}, &mockConnector{}, logger, Config{})
+	got, err := r.Clusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected all clusters, got %v", got)
+	}
+
+	r = New(&mockReviewer{}, &mockClusterLister{clusters: slices.Clone(clusters)}, &mockConnector{}, logger, Config{Clusters: []string{"prod", "c-xyz12"}})
+	got, err = r.Clusters(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "prod" || got[1].Name != "legacy" {
+		t.Errorf("expected prod and legacy by name and ID, got %v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset(newTestNamespace("unassigned", "platform", nil))
+	r, reviewer := newTestReconciler(ModePatch, map[string]kubernetes.Interface{"c-m-abc123": client},
+		rancher.Cluster{Name: "unreachable", ID: "c-m-def456"},
+		rancher.Cluster{Name: "prod", ID: "c-m-abc123"},
+	)
+
+	// An unreachable cluster doesn't stop the others from being reconciled
+	r.Run(context.Background())
+
+	if len(reviewer.users) != 1 {
+		t.Errorf("expected 1 namespace to be reviewed, got %d", len(reviewer.users))
+	}
+	namespace, err := client.CoreV1().Namespaces().Get(context.Background(), "unassigned", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if namespace.Annotations[testAnnotation] == "" {
+		t.Error("expected namespace in the reachable cluster to be patched")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, valid := range []string{"patch", " report "} {
+		if _, err := ParseMode(valid); err != nil {
+			t.Errorf("unexpected error for %q: %v", valid, err)
+		}
+	}
+	if _, err := ParseMode("apply"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,45 @@
+package reconciler
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Count returns the number of results of each action
+func Count(results []Result) map[Action]int {
+	counts := make(map[Action]int)
+	for _, result := range results {
+		counts[result.Action]++
+	}
+	return counts
+}
+
+// WriteTable writes the results of namespaces that aren't in sync as a table
+// of their current and desired project annotations
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLUSTER\tNAMESPACE\tCURRENT\tDESIRED\tACTION\tMESSAGE")
+	for _, result := range results {
+		if result.Action == ActionInSync {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Cluster,
+			result.Namespace,
+			orNone(result.Current),
+			orNone(result.Desired),
+			result.Action,
+			result.Message,
+		)
+	}
+	return tw.Flush()
+}
+
+// orNone shows an empty annotation as "-"
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package reconciler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	counts := Count([]Result{
+		{Namespace: "a", Action: ActionInSync},
+		{Namespace: "b", Action: ActionDrift},
+		{Namespace: "c", Action: ActionDrift},
+		{Namespace: "d", Action: ActionFailed},
+	})
+
+	want := map[Action]int{ActionInSync: 1, ActionDrift: 2, ActionFailed: 1}
+	if len(counts) != len(want) {
+		t.Fatalf("Count() = %v, want %v", counts, want)
+	}
+	for action, n := range want {
+		if counts[action] != n {
+			t.Errorf("Count()[%s] = %d, want %d", action, counts[action], n)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTable(&buf, []Result{
+		{Cluster: "prod", Namespace: "in-sync", Current: "c-abc:p-123", Action: ActionInSync},
+		{Cluster: "prod", Namespace: "team-a", Desired: "c-abc:p-123", Action: ActionDrift},
+		{Cluster: "prod", Namespace: "team-b", Current: "c-abc:p-old", Action: ActionDenied, Message: "project not allowed"},
+	})
+	if err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := [][]string{
+		{"CLUSTER", "NAMESPACE", "CURRENT", "DESIRED", "ACTION", "MESSAGE"},
+		{"prod", "team-a", "-", "c-abc:p-123", "drift"},
+		{"prod", "team-b", "c-abc:p-old", "-", "denied", "project", "not", "allowed"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("WriteTable() wrote %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, fields := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("line %d = %q, want fields %q", i, lines[i], fields)
+		}
+	}
+}
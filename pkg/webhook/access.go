@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// serviceAccountPrefix is the username prefix of service accounts
+const serviceAccountPrefix = "system:serviceaccount:"
+
+// ProjectAccessAction decides what happens when the requesting user may not
+// assign a namespace to its project
+type ProjectAccessAction string
+
+const (
+	// ProjectAccessDeny rejects the request
+	ProjectAccessDeny ProjectAccessAction = "deny"
+	// ProjectAccessStrip admits the namespace without assigning it to the project
+	ProjectAccessStrip ProjectAccessAction = "strip"
+)
+
+// ProjectAccessRule allows users, groups and service accounts to assign
+// namespaces to projects. Projects are names or IDs, "*" matches any project,
+// and service accounts are given as namespace:name.
+type ProjectAccessRule struct {
+	Projects        []string `json:"projects"`
+	Users           []string `json:"users"`
+	Groups          []string `json:"groups"`
+	ServiceAccounts []string `json:"serviceAccounts"`
+}
+
+// ProjectAccessPolicy restricts which projects the requesting user may assign
+// namespaces to
+type ProjectAccessPolicy struct {
+	// Action applies to users no rule allows (default: deny)
+	Action ProjectAccessAction `json:"action"`
+	// CheckMembership also allows members of the project in Rancher
+	CheckMembership bool                `json:"checkMembership"`
+	Rules           []ProjectAccessRule `json:"rules"`
+}
+
+// LoadProjectAccessPolicy reads a project access policy from a YAML file of the form
+//
+//	action: deny
+//	checkMembership: true
+//	rules:
+//	  - projects: ["*"]
+//	    groups: ["platform-admins"]
+//	  - projects: [payments]
+//	    serviceAccounts: ["argocd:argocd-application-controller"]
+func LoadProjectAccessPolicy(policyFile string) (*ProjectAccessPolicy, error) {
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project access file: %w", err)
+	}
+
+	policy, err := parseProjectAccessPolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project access file %s: %w", policyFile, err)
+	}
+	return policy, nil
+}
+
+// parseProjectAccessPolicy validates a project access file
+func parseProjectAccessPolicy(data []byte) (*ProjectAccessPolicy, error) {
+	var policy ProjectAccessPolicy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, err
+	}
+
+	switch policy.Action {
+	case "":
+		policy.Action = ProjectAccessDeny
+	case ProjectAccessDeny, ProjectAccessStrip:
+	default:
+		return nil, fmt.Errorf("invalid action %q (must be deny or strip)", policy.Action)
+	}
+
+	for i, rule := range policy.Rules {
+		if len(rule.Projects) == 0 {
+			return nil, fmt.Errorf("rule %d: projects must not be empty", i+1)
+		}
+		if len(rule.Users) == 0 && len(rule.Groups) == 0 && len(rule.ServiceAccounts) == 0 {
+			return nil, fmt.Errorf("rule %d: at least one of users, groups or serviceAccounts is required", i+1)
+		}
+		for _, sa := range rule.ServiceAccounts {
+			if ns, name, ok := strings.Cut(sa, ":"); !ok || ns == "" || name == "" {
+				return nil, fmt.Errorf("rule %d: invalid service account %q (must be namespace:name)", i+1, sa)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// allows reports whether a rule lets user assign namespaces to a project
+func (r ProjectAccessRule) allows(user userRef, projectName, projectID string) bool {
+	if !slices.Contains(r.Projects, "*") && !slices.Contains(r.Projects, projectName) && !slices.Contains(r.Projects, projectID) {
+		return false
+	}
+	if slices.Contains(r.Users, user.username) {
+		return true
+	}
+	for _, group := range user.groups {
+		if slices.Contains(r.Groups, group) {
+			return true
+		}
+	}
+	if sa, ok := strings.CutPrefix(user.username, serviceAccountPrefix); ok && slices.Contains(r.ServiceAccounts, sa) {
+		return true
+	}
+	return false
+}
+
+// userRef is the user an admission request was made by
+type userRef struct {
+	username string
+	groups   []string
+}
+
+// projectAccessAllowed reports whether the requesting user may assign a
+// namespace to a project, by the policy's rules or, failing those, by
+// membership of the project in Rancher. A project that doesn't exist yet
+// (empty projectID) has no members.
+func (h *Handler) projectAccessAllowed(ctx context.Context, user userRef, clusterID, projectName, projectID string) (bool, error) {
+	if h.projectAccessRulesAllow(user, projectName, projectID) {
+		return true, nil
+	}
+	if !h.projectAccess.CheckMembership || projectID == "" {
+		return false, nil
+	}
+	return h.rancherClient.IsProjectMember(ctx, clusterID, projectID, user.username, user.groups)
+}
+
+// projectAccessRulesAllow reports whether a rule of the project access policy
+// lets user assign namespaces to a project
+func (h *Handler) projectAccessRulesAllow(user userRef, projectName, projectID string) bool {
+	for _, rule := range h.projectAccess.Rules {
+		if rule.allows(user, projectName, projectID) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignedTo reports whether the namespace before the request was already in the project
+func assignedTo(oldNamespace *corev1.Namespace, projectAnnotation, projectAnnotationValue string) bool {
+	return oldNamespace != nil && oldNamespace.Annotations[projectAnnotation] == projectAnnotationValue
+}
+
+// checkProjectSource refuses a project source that targets a protected
+// project or one the requester may not assign namespaces to. projectID is
+// empty for a project that doesn't exist yet. It returns nil when the
+// assignment may go ahead.
+func (h *Handler) checkProjectSource(ctx context.Context, req *admissionv1.AdmissionRequest, namespace, oldNamespace *corev1.Namespace, clusterName, clusterID, projectName, source, projectID string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	if h.isProjectProtected(ctx, clusterID, projectName, projectID, logger) {
+		response, status := h.refuseProtectedProject(namespace.Name, clusterName, projectName, source, projectID, logger)
+		return h.keepPreviousAssignment(response, namespace, oldNamespace), status
+	}
+
+	// A namespace that was already in the project was authorized when it was
+	// assigned. The annotation in the request itself is the requester's, and an
+	// unchanged label proves nothing when the assignment was stripped.
+	if h.projectAccess == nil || (projectID != "" && assignedTo(oldNamespace, h.projectAnnotation, clusterID+":"+projectID)) {
+		return nil, ""
+	}
+	response, status := h.authorizeProject(ctx, req, namespace.Name, clusterName, clusterID, projectName, projectID, logger)
+	if response != nil {
+		response = h.keepPreviousAssignment(response, namespace, oldNamespace)
+	}
+	return response, status
+}
+
+// authorizeProject applies the project access policy to a request that
+// assigns a namespace to a project from one of its project sources. It
+// returns nil when the assignment may go ahead.
+func (h *Handler) authorizeProject(ctx context.Context, req *admissionv1.AdmissionRequest, namespaceName, clusterName, clusterID, projectName, projectID string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	user := userRef{username: req.UserInfo.Username, groups: req.UserInfo.Groups}
+
+	allowed, err := h.projectAccessAllowed(ctx, user, clusterID, projectName, projectID)
+	if err != nil {
+		// Fail closed: an unverified membership is not a membership
+		logger.Error("Failed to check project membership",
+			slog.String("namespace", namespaceName),
+			slog.String("cluster", clusterName),
+			slog.String("project", projectName),
+			slog.String("user", user.username),
+			slog.String("error", err.Error()),
+		)
+	}
+	if allowed {
+		return nil, ""
+	}
+
+	if !h.reviewing {
+		metrics.ProjectAccessDenialsTotal.WithLabelValues(string(h.projectAccess.Action)).Inc()
+	}
+	attrs := []any{
+		slog.String("namespace", namespaceName),
+		slog.String("cluster", clusterName),
+		slog.String("project", projectName),
+		slog.String("project_id", projectID),
+		slog.String("user", user.username),
+		slog.Any("groups", user.groups),
+		slog.String("action", string(h.projectAccess.Action)),
+	}
+
+	if h.projectAccess.Action == ProjectAccessStrip {
+		logger.Warn("User may not assign namespaces to project, admitting namespace without project annotation", attrs...)
+		return notAssigned(reasonAccessDenied, projectName,
+			fmt.Sprintf("user %s is not allowed to assign namespaces to project '%s'; namespace %s was not assigned to it",
+				user.username, projectName, namespaceName)), metrics.StatusAllowed
+	}
+
+	message := fmt.Sprintf("user %s is not allowed to assign namespaces to project '%s'", user.username, projectName)
+	if projectID != "" {
+		message += fmt.Sprintf(" (%s:%s)", clusterID, projectID)
+	}
+	logger.Warn("User may not assign namespaces to project, denying", attrs...)
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result:  &metav1.Status{Message: message},
+	}, metrics.StatusDenied
+}
@@ -0,0 +1,240 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseProjectAccessPolicy(t *testing.T) {
+	policy, err := parseProjectAccessPolicy([]byte(`
+checkMembership: true
+rules:
+  - projects: ["*"]
+    groups: [platform-admins]
+  - projects: [payments, p-xyz789]
+    serviceAccounts: ["argocd:argocd-application-controller"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Action != ProjectAccessDeny {
+		t.Errorf("expected action to default to deny, got %q", policy.Action)
+	}
+	if !policy.CheckMembership || len(policy.Rules) != 2 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	invalid := map[string]string{
+		"unknown action":          "action: warn",
+		"unknown field":           "rules:\n  - projects: [a]\n    users: [u]\n    roles: [owner]",
+		"rule without projects":   "rules:\n  - users: [u]",
+		"rule without subjects":   "rules:\n  - projects: [a]",
+		"malformed service acct":  "rules:\n  - projects: [a]\n    serviceAccounts: [argocd]",
+		"service acct without ns": "rules:\n  - projects: [a]\n    serviceAccounts: [\":argocd\"]",
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProjectAccessPolicy([]byte(data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestProjectAccessRule_Allows(t *testing.T) {
+	rule := ProjectAccessRule{
+		Projects:        []string{"payments", "p-xyz789"},
+		Users:           []string{"alice"},
+		Groups:          []string{"payments-devs"},
+		ServiceAccounts: []string{"argocd:argocd-application-controller"},
+	}
+
+	tests := []struct {
+		name        string
+		user        userRef
+		projectName string
+		projectID   string
+		expected    bool
+	}{
+		{name: "user by project name", user: userRef{username: "alice"}, projectName: "payments", projectID: "p-abc12", expected: true},
+		{name: "user by project ID", user: userRef{username: "alice"}, projectName: "platform", projectID: "p-xyz789", expected: true},
+		{name: "group", user: userRef{username: "bob", groups: []string{"system:authenticated", "payments-devs"}}, projectName: "payments", expected: true},
+		{name: "service account", user: userRef{username: "system:serviceaccount:argocd:argocd-application-controller"}, projectName: "payments", expected: true},
+		{name: "service account name is not a username", user: userRef{username: "argocd:argocd-application-controller"}, projectName: "payments", expected: false},
+		{name: "other project", user: userRef{username: "alice"}, projectName: "System", projectID: "p-sys01", expected: false},
+		{name: "other user", user: userRef{username: "mallory"}, projectName: "payments", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.allows(tt.user, tt.projectName, tt.projectID); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	wildcard := ProjectAccessRule{Projects: []string{"*"}, Groups: []string{"platform-admins"}}
+	if !wildcard.allows(userRef{username: "carol", groups: []string{"platform-admins"}}, "System", "p-sys01") {
+		t.Error("expected wildcard rule to allow any project")
+	}
+}
+
+func TestMutate_ProjectAccess(t *testing.T) {
+	rules := []ProjectAccessRule{{Projects: []string{"platform"}, Groups: []string{"platform-devs"}}}
+
+	tests := []struct {
+		name           string
+		policy         ProjectAccessPolicy
+		client         *mockRancherClient
+		user           authenticationv1.UserInfo
+		expectedStatus string
+		expectAllowed  bool
+	}{
+		{
+			name:           "allowed by rule",
+			policy:         ProjectAccessPolicy{Action: ProjectAccessDeny, Rules: rules},
+			user:           authenticationv1.UserInfo{Username: "bob", Groups: []string{"platform-devs"}},
+			expectedStatus: metrics.StatusMutated,
+			expectAllowed:  true,
+		},
+		{
+			name:           "denied",
+			policy:         ProjectAccessPolicy{Action: ProjectAccessDeny, Rules: rules},
+			user:           authenticationv1.UserInfo{Username: "mallory"},
+			expectedStatus: metrics.StatusDenied,
+		},
+		{
+			name:           "stripped",
+			policy:         ProjectAccessPolicy{Action: ProjectAccessStrip, Rules: rules},
+			user:           authenticationv1.UserInfo{Username: "mallory"},
+			expectedStatus: metrics.StatusAllowed,
+			expectAllowed:  true,
+		},
+		{
+			name:           "allowed as project member",
+			policy:         ProjectAccessPolicy{Action: ProjectAccessDeny, CheckMembership: true},
+			client:         &mockRancherClient{members: []string{"u-alice"}},
+			user:           authenticationv1.UserInfo{Username: "u-alice"},
+			expectedStatus: metrics.StatusMutated,
+			expectAllowed:  true,
+		},
+		{
+			name:           "membership lookup failure denies",
+			policy:         ProjectAccessPolicy{Action: ProjectAccessDeny, CheckMembership: true},
+			client:         &mockRancherClient{members: []string{"u-alice"}, memberErr: errors.New("connection refused")},
+			user:           authenticationv1.UserInfo{Username: "u-alice"},
+			expectedStatus: metrics.StatusDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ProjectAccessDenialsTotal.Reset()
+
+			client := tt.client
+			if client == nil {
+				client = &mockRancherClient{}
+			}
+			client.clusterID, client.projectID = "c-m-abc123", "p-xyz789"
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.ProjectAccess = &tt.policy
+			handler := NewHandler(client, logger, cfg)
+
+			review := createAdmissionReview(newTestNamespace("platform", ""), admissionv1.Create)
+			review.Request.UserInfo = tt.user
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if response.Allowed != tt.expectAllowed {
+				t.Errorf("expected allowed=%v, got %v", tt.expectAllowed, response.Allowed)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+
+			expectedDenials := 0.0
+			if tt.expectedStatus != metrics.StatusMutated {
+				expectedDenials = 1
+				if response.Patch != nil {
+					t.Errorf("expected no patch, got %s", response.Patch)
+				}
+			}
+			if got := testutil.ToFloat64(metrics.ProjectAccessDenialsTotal.WithLabelValues(string(tt.policy.Action))); got != expectedDenials {
+				t.Errorf("expected %v access denials, got %v", expectedDenials, got)
+			}
+		})
+	}
+}
+
+func TestMutate_ProjectAccessStripsRequestAnnotation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ProjectAccess = &ProjectAccessPolicy{Action: ProjectAccessStrip}
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, cfg)
+
+	// The requester sets the annotation of a project it may not use along with the label
+	review := createAdmissionReview(newTestNamespace("platform", "c-m-abc123:p-xyz789"), admissionv1.Create)
+	review.Request.UserInfo.Username = "mallory"
+
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+	if !response.Allowed || status != metrics.StatusAllowed {
+		t.Fatalf("expected namespace to be admitted unassigned, got allowed=%v and status '%s'", response.Allowed, status)
+	}
+	expected := `[{"op":"remove","path":"/metadata/annotations/field.cattle.io~1projectId"}]`
+	if string(response.Patch) != expected {
+		t.Errorf("expected patch %s, got %s", expected, response.Patch)
+	}
+}
+
+func TestMutate_ProjectAccessSkipsAssignedAndDefaultProjects(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.DefaultProject = "sandbox"
+	cfg.ProjectAccess = &ProjectAccessPolicy{Action: ProjectAccessStrip}
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, cfg)
+	mallory := authenticationv1.UserInfo{Username: "mallory"}
+
+	// A stripped namespace keeps its label, which must not assign it on the next update
+	create := createAdmissionReview(newTestNamespace("platform", ""), admissionv1.Create).Request
+	create.UserInfo = mallory
+	response, status := handler.mutate(context.Background(), create, clusterRef{name: "test-cluster"}, logger)
+	if status != metrics.StatusAllowed || response.Patch != nil {
+		t.Fatalf("expected create to be stripped, got status '%s' and patch %s", status, response.Patch)
+	}
+	stripped := newTestNamespace("platform", "")
+	relabeled := stripped.DeepCopy()
+	relabeled.Labels["team"] = "mallory"
+	update := createAdmissionReviewWithOld(relabeled, stripped, admissionv1.Update).Request
+	update.UserInfo = mallory
+	response, status = handler.mutate(context.Background(), update, clusterRef{name: "test-cluster"}, logger)
+	if status != metrics.StatusAllowed || response.Patch != nil {
+		t.Errorf("expected follow-up update to be stripped, got status '%s' and patch %s", status, response.Patch)
+	}
+
+	// A namespace already in the project has its removed annotation restored for anyone
+	assigned := stripped.DeepCopy()
+	assigned.Annotations = map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"}
+	update = createAdmissionReviewWithOld(relabeled, assigned, admissionv1.Update).Request
+	update.UserInfo = mallory
+	if _, status := handler.mutate(context.Background(), update, clusterRef{name: "test-cluster"}, logger); status != metrics.StatusMutated {
+		t.Errorf("expected assigned namespace to be mutated, got status '%s'", status)
+	}
+
+	// The default project is assigned by the administrator, not the requester
+	unlabeled := createAdmissionReview(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}}, admissionv1.Create).Request
+	unlabeled.UserInfo = mallory
+	if _, status := handler.mutate(context.Background(), unlabeled, clusterRef{name: "test-cluster"}, logger); status != metrics.StatusMutated {
+		t.Errorf("expected default project to be mutated, got status '%s'", status)
+	}
+}
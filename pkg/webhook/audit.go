@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// Audit annotation keys. The API server prefixes them with the webhook name
+// (e.g. fencemaster.fencemaster.svc/decision) in the audit log.
+const (
+	auditDecision   = "decision"
+	auditReason     = "reason"
+	auditProject    = "project"
+	auditSource     = "source"
+	auditAnnotation = "annotation"
+)
+
+// Decisions recorded in the audit log
+const (
+	decisionAssigned    = "assigned"
+	decisionUnassigned  = "unassigned"
+	decisionNotAssigned = "not_assigned"
+	decisionUnvalidated = "not_validated"
+	// decisionWouldCreate never reaches the audit log: it tells a report-only
+	// review that the namespace's project would have to be created
+	decisionWouldCreate = "would_create_project"
+)
+
+// Reasons recorded in the audit log and logs when a namespace is admitted
+// without being assigned to its project
+const (
+	reasonClusterNotFound    = "cluster_not_found"
+	reasonProjectNotFound    = "project_not_found"
+	reasonProtectedProject   = "protected_project"
+	reasonAccessDenied       = "access_denied"
+	reasonAnnotationConflict = "annotation_conflict"
+	reasonLookupFailed       = "lookup_failed"
+)
+
+// notAssigned admits a namespace without assigning it to its project. The
+// warning is shown to whoever made the request (kubectl, Flux and Argo CD all
+// surface admission warnings) and the reason is recorded in the audit log.
+func notAssigned(reason, project, warning string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed:  true,
+		Warnings: []string{warning},
+		AuditAnnotations: map[string]string{
+			auditDecision: decisionNotAssigned,
+			auditReason:   reason,
+			auditProject:  project,
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMutate_SoftFailureWarnings(t *testing.T) {
+	labeled := func(project string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "team-a",
+				Labels: map[string]string{"project": project},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		client         *mockRancherClient
+		configure      func(cfg *HandlerConfig)
+		namespace      *corev1.Namespace
+		expectedReason string
+		expectWarning  string
+	}{
+		{
+			name:           "cluster not found",
+			client:         &mockRancherClient{clusterErr: errors.New("cluster test-cluster not found")},
+			namespace:      labeled("platform"),
+			expectedReason: reasonClusterNotFound,
+			expectWarning:  "cluster test-cluster not found; namespace team-a was not assigned to project 'platform'",
+		},
+		{
+			name:           "project not found",
+			client:         &mockRancherClient{clusterID: "c-m-abc123", projectErr: errors.New("project platfrom not found in cluster c-m-abc123")},
+			namespace:      labeled("platfrom"),
+			expectedReason: reasonProjectNotFound,
+			expectWarning:  "project platfrom not found in cluster c-m-abc123; namespace team-a was not assigned to a project",
+		},
+		{
+			name:           "protected project",
+			client:         &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-sys01"},
+			configure:      func(cfg *HandlerConfig) { cfg.ProtectedProjects = []string{"System"} },
+			namespace:      labeled("System"),
+			expectedReason: reasonProtectedProject,
+			expectWarning:  "project 'System' is protected",
+		},
+		{
+			name:   "access stripped",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProjectAccess = &ProjectAccessPolicy{Action: ProjectAccessStrip}
+			},
+			namespace:      labeled("platform"),
+			expectedReason: reasonAccessDenied,
+			expectWarning:  "is not allowed to assign namespaces to project 'platform'",
+		},
+		{
+			name:           "annotation wins",
+			client:         &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			configure:      func(cfg *HandlerConfig) { cfg.ConflictPolicy = ConflictAnnotationWins },
+			namespace:      newTestNamespace("platform", "c-m-abc123:p-manual"),
+			expectedReason: reasonAnnotationConflict,
+			expectWarning:  "keeps field.cattle.io/projectId=c-m-abc123:p-manual",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			if tt.configure != nil {
+				tt.configure(&cfg)
+			}
+			handler := NewHandler(tt.client, logger, cfg)
+
+			review := createAdmissionReview(tt.namespace, admissionv1.Create)
+			response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if !response.Allowed {
+				t.Fatalf("expected namespace to be allowed, got %+v", response.Result)
+			}
+			if response.Patch != nil {
+				t.Errorf("expected no patch, got %s", response.Patch)
+			}
+			if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], tt.expectWarning) {
+				t.Errorf("expected a warning containing %q, got %q", tt.expectWarning, response.Warnings)
+			}
+			if got := response.AuditAnnotations[auditDecision]; got != decisionNotAssigned {
+				t.Errorf("expected audit decision '%s', got '%s'", decisionNotAssigned, got)
+			}
+			if got := response.AuditAnnotations[auditReason]; got != tt.expectedReason {
+				t.Errorf("expected audit reason '%s', got '%s'", tt.expectedReason, got)
+			}
+		})
+	}
+}
+
+func TestMutate_AssignedAuditAnnotations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, testHandlerConfig())
+
+	review := createAdmissionReview(newTestNamespace("platform", ""), admissionv1.Create)
+	response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+	expected := map[string]string{
+		auditDecision:   decisionAssigned,
+		auditProject:    "platform",
+		auditSource:     "label:project",
+		auditAnnotation: "c-m-abc123:p-xyz789",
+	}
+	for key, value := range expected {
+		if got := response.AuditAnnotations[key]; got != value {
+			t.Errorf("expected audit annotation %s=%s, got %q", key, value, got)
+		}
+	}
+	if len(response.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %q", response.Warnings)
+	}
+}
+
+func TestValidate_LookupFailureWarning(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewHandler(&mockRancherClient{clusterErr: errors.New("connection refused")}, logger, testHandlerConfig())
+
+	review := createAdmissionReview(newTestNamespace("platform", "c-m-abc123:p-xyz789"), admissionv1.Create)
+	response, _ := handler.validate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+	if !response.Allowed {
+		t.Fatalf("expected namespace to be allowed in permissive mode, got %+v", response.Result)
+	}
+	if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "was not validated") {
+		t.Errorf("expected a warning that the annotation was not validated, got %q", response.Warnings)
+	}
+	if got := response.AuditAnnotations[auditDecision]; got != decisionUnvalidated {
+		t.Errorf("expected audit decision '%s', got '%s'", decisionUnvalidated, got)
+	}
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConflictPolicy decides what happens when a namespace carries a project
+// annotation set outside fencemaster (e.g. in the Rancher UI) that disagrees
+// with its project label
+type ConflictPolicy string
+
+const (
+	// ConflictLabelWins overwrites the annotation with the label's project
+	ConflictLabelWins ConflictPolicy = "label-wins"
+	// ConflictAnnotationWins keeps the annotation
+	ConflictAnnotationWins ConflictPolicy = "annotation-wins"
+	// ConflictDeny rejects the request
+	ConflictDeny ConflictPolicy = "deny"
+)
+
+// ParseConflictPolicy validates a conflict policy name
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(strings.TrimSpace(s)); policy {
+	case ConflictLabelWins, ConflictAnnotationWins, ConflictDeny:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q (must be label-wins, annotation-wins or deny)", s)
+	}
+}
+
+// annotationConflicts reports whether the namespace's project annotation
+// disagrees with the annotation its project resolves to. An annotation left
+// over from the previous project when an UPDATE changes the project label is
+// not a conflict: the label is moving the namespace.
+func (h *Handler) annotationConflicts(namespace, oldNamespace *corev1.Namespace, projectName, annotation string) bool {
+	current := namespace.Annotations[h.projectAnnotation]
+	if current == "" || current == annotation {
+		return false
+	}
+	if oldNamespace == nil {
+		return true
+	}
+
+	oldProjectName, _ := h.projectFromSources(oldNamespace)
+	return oldProjectName == projectName || current != oldNamespace.Annotations[h.projectAnnotation]
+}
+
+// resolveConflict applies the conflict policy to a namespace whose project
+// annotation disagrees with its project. It returns nil when the annotation
+// should be overwritten.
+func (h *Handler) resolveConflict(namespace *corev1.Namespace, clusterName, projectName, source, annotation string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	current := namespace.Annotations[h.projectAnnotation]
+	if !h.reviewing {
+		metrics.ProjectConflictsTotal.WithLabelValues(string(h.conflictPolicy)).Inc()
+	}
+
+	attrs := []any{
+		slog.String("namespace", namespace.Name),
+		slog.String("cluster", clusterName),
+		slog.String("project", projectName),
+		slog.String("source", source),
+		slog.String("annotation", current),
+		slog.String("expected_annotation", annotation),
+		slog.String("policy", string(h.conflictPolicy)),
+	}
+
+	switch h.conflictPolicy {
+	case ConflictAnnotationWins:
+		logger.Warn("Project annotation conflicts with project label, keeping annotation", attrs...)
+		return notAssigned(reasonAnnotationConflict, projectName,
+			fmt.Sprintf("namespace %s keeps %s=%s; %s (project '%s') is ignored",
+				namespace.Name, h.projectAnnotation, current, source, projectName)), metrics.StatusSkipped
+	case ConflictDeny:
+		logger.Warn("Project annotation conflicts with project label, denying", attrs...)
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: fmt.Sprintf("namespace %s has %s=%s, but %s assigns it to project '%s' (%s): change the label or remove the annotation",
+					namespace.Name, h.projectAnnotation, current, source, projectName, annotation),
+			},
+		}, metrics.StatusDenied
+	default:
+		logger.Warn("Project annotation conflicts with project label, overwriting annotation", attrs...)
+		return nil, ""
+	}
+}
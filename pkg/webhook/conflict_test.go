@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, valid := range []string{"label-wins", "annotation-wins", "deny"} {
+		if _, err := ParseConflictPolicy(valid); err != nil {
+			t.Errorf("unexpected error for %q: %v", valid, err)
+		}
+	}
+	if _, err := ParseConflictPolicy("merge"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestMutate_AnnotationConflict(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         ConflictPolicy
+		expectedStatus string
+		expectAllowed  bool
+	}{
+		{name: "label wins by default", expectedStatus: metrics.StatusMutated, expectAllowed: true},
+		{name: "annotation wins", policy: ConflictAnnotationWins, expectedStatus: metrics.StatusSkipped, expectAllowed: true},
+		{name: "deny", policy: ConflictDeny, expectedStatus: metrics.StatusDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ProjectConflictsTotal.Reset()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.ConflictPolicy = tt.policy
+			handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, cfg)
+
+			review := createAdmissionReview(newTestNamespace("platform", "c-m-abc123:p-manual"), admissionv1.Create)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if response.Allowed != tt.expectAllowed {
+				t.Errorf("expected allowed=%v, got %v", tt.expectAllowed, response.Allowed)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if !tt.expectAllowed && !strings.Contains(response.Result.Message, "c-m-abc123:p-manual") {
+				t.Errorf("expected denial message to name the conflicting annotation, got %q", response.Result.Message)
+			}
+
+			policy := tt.policy
+			if policy == "" {
+				policy = ConflictLabelWins
+			}
+			if got := testutil.ToFloat64(metrics.ProjectConflictsTotal.WithLabelValues(string(policy))); got != 1 {
+				t.Errorf("expected 1 conflict for policy %s, got %f", policy, got)
+			}
+		})
+	}
+}
+
+func TestMutate_AnnotationChangedWithUnchangedLabel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ConflictPolicy = ConflictDeny
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, cfg)
+
+	// Moving the namespace to another project in the Rancher UI only changes the annotation
+	review := createAdmissionReviewWithOld(newTestNamespace("platform", "c-m-abc123:p-manual"), newTestNamespace("platform", "c-m-abc123:p-xyz789"), admissionv1.Update)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+	if response.Allowed {
+		t.Error("expected request to be denied")
+	}
+	if status != metrics.StatusDenied {
+		t.Errorf("expected status '%s', got '%s'", metrics.StatusDenied, status)
+	}
+}
+
+func TestAnnotationConflicts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewHandler(nil, logger, testHandlerConfig())
+
+	relabeled := func(project, annotation string) *corev1.Namespace {
+		ns := newTestNamespace("platform", annotation)
+		ns.Labels["project"] = project
+		return ns
+	}
+
+	tests := []struct {
+		name     string
+		ns       *corev1.Namespace
+		oldNs    *corev1.Namespace
+		expected bool
+	}{
+		{name: "no annotation", ns: newTestNamespace("platform", ""), expected: false},
+		{name: "matching annotation", ns: newTestNamespace("platform", "c-m-abc123:p-xyz789"), expected: false},
+		{name: "different annotation on create", ns: newTestNamespace("platform", "c-m-abc123:p-manual"), expected: true},
+		{
+			name:     "label moved the namespace",
+			ns:       newTestNamespace("platform", "c-m-abc123:p-old12"),
+			oldNs:    relabeled("legacy", "c-m-abc123:p-old12"),
+			expected: false,
+		},
+		{
+			name:     "annotation changed with the label",
+			ns:       newTestNamespace("platform", "c-m-abc123:p-manual"),
+			oldNs:    relabeled("legacy", "c-m-abc123:p-old12"),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handler.annotationConflicts(tt.ns, tt.oldNs, "platform", "c-m-abc123:p-xyz789"); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
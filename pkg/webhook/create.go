@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// isNotFound reports whether err is a lookup failure caused by a missing
+// object rather than by the API, as marked by the Rancher client
+func isNotFound(err error) bool {
+	var nf interface{ NotFound() bool }
+	return errors.As(err, &nf) && nf.NotFound()
+}
+
+// shouldCreateProject reports whether a project that wasn't found may be
+// created. Only display names are created: an unknown project ID is a typo,
+// not a new project. The caller still checks the project source against the
+// protected projects and the project access policy before creating it.
+func (h *Handler) shouldCreateProject(projectName string, lookupErr error) bool {
+	if !h.createProjects || !isNotFound(lookupErr) {
+		return false
+	}
+	return !projectIDPattern.MatchString(projectName) && !qualifiedProjectIDPattern.MatchString(projectName)
+}
+
+// createProject creates a missing project for a namespace and returns its ID
+func (h *Handler) createProject(ctx context.Context, namespaceName, clusterName, clusterID, projectName, source string, logger *slog.Logger) (string, error) {
+	projectID, err := h.rancherClient.CreateProject(ctx, clusterID, projectName)
+	if err != nil {
+		logger.Error("Failed to create project",
+			slog.String("namespace", namespaceName),
+			slog.String("cluster", clusterName),
+			slog.String("cluster_id", clusterID),
+			slog.String("project", projectName),
+			slog.String("source", source),
+			slog.String("error", err.Error()),
+		)
+		return "", err
+	}
+
+	logger.Info("Created missing project for namespace",
+		slog.String("namespace", namespaceName),
+		slog.String("cluster", clusterName),
+		slog.String("cluster_id", clusterID),
+		slog.String("project", projectName),
+		slog.String("source", source),
+		slog.String("project_id", projectID),
+	)
+	return projectID, nil
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsNotFound(t *testing.T) {
+	if !isNotFound(fmt.Errorf("lookup: %w", rancher.NewNotFoundError(errors.New("project payments not found")))) {
+		t.Error("expected wrapped not-found error to be detected")
+	}
+	if isNotFound(errors.New("connection refused")) {
+		t.Error("expected API error not to be a not-found error")
+	}
+}
+
+func TestMutate_CreateProjects(t *testing.T) {
+	missing := rancher.NewNotFoundError(errors.New("project payments not found in cluster c-m-abc123"))
+
+	tests := []struct {
+		name           string
+		label          string
+		createProjects bool
+		dryRun         bool
+		projectErr     error
+		createErr      error
+		configure      func(cfg *HandlerConfig)
+		expectCreated  bool
+		expectDenied   bool
+		expectedStatus string
+	}{
+		{name: "disabled", label: "payments", projectErr: missing, expectedStatus: metrics.StatusAllowed},
+		{name: "creates missing project", label: "payments", createProjects: true, projectErr: missing, expectCreated: true, expectedStatus: metrics.StatusMutated},
+		{name: "dry run", label: "payments", createProjects: true, dryRun: true, projectErr: missing, expectedStatus: metrics.StatusDryRun},
+		{name: "lookup API error", label: "payments", createProjects: true, projectErr: errors.New("connection refused"), expectedStatus: metrics.StatusAllowed},
+		{name: "unknown project ID", label: "p-zzz99", createProjects: true, projectErr: missing, expectedStatus: metrics.StatusAllowed},
+		{name: "creation fails", label: "payments", createProjects: true, projectErr: missing, createErr: errors.New("forbidden"), expectedStatus: metrics.StatusAllowed},
+		{
+			name:           "protected project",
+			label:          "System",
+			createProjects: true,
+			projectErr:     missing,
+			configure:      func(cfg *HandlerConfig) { cfg.ProtectedProjects = []string{"System"} },
+			expectedStatus: metrics.StatusSkipped,
+		},
+		{
+			name:           "protected project in strict mode",
+			label:          "System",
+			createProjects: true,
+			projectErr:     missing,
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProtectedProjects = []string{"System"}
+				cfg.StrictMode = true
+			},
+			expectDenied:   true,
+			expectedStatus: metrics.StatusDenied,
+		},
+		{
+			name:           "protected project in dry run",
+			label:          "System",
+			createProjects: true,
+			dryRun:         true,
+			projectErr:     missing,
+			configure:      func(cfg *HandlerConfig) { cfg.ProtectedProjects = []string{"System"} },
+			expectedStatus: metrics.StatusSkipped,
+		},
+		{
+			// A project that doesn't exist yet has no members
+			name:           "access policy denies requester",
+			label:          "payments",
+			createProjects: true,
+			projectErr:     missing,
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProjectAccess = &ProjectAccessPolicy{Action: ProjectAccessDeny, CheckMembership: true}
+			},
+			expectDenied:   true,
+			expectedStatus: metrics.StatusDenied,
+		},
+		{
+			name:           "access policy strips requester",
+			label:          "payments",
+			createProjects: true,
+			dryRun:         true,
+			projectErr:     missing,
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProjectAccess = &ProjectAccessPolicy{
+					Action: ProjectAccessStrip,
+					Rules:  []ProjectAccessRule{{Projects: []string{"payments"}, Users: []string{"bob"}}},
+				}
+			},
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "access policy allows requester by display name",
+			label:          "payments",
+			createProjects: true,
+			projectErr:     missing,
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProjectAccess = &ProjectAccessPolicy{
+					Action: ProjectAccessDeny,
+					Rules:  []ProjectAccessRule{{Projects: []string{"payments"}, Users: []string{"alice"}}},
+				}
+			},
+			expectCreated:  true,
+			expectedStatus: metrics.StatusMutated,
+		},
+		{
+			name:           "access policy allows requester",
+			label:          "payments",
+			createProjects: true,
+			projectErr:     missing,
+			configure: func(cfg *HandlerConfig) {
+				cfg.ProjectAccess = &ProjectAccessPolicy{
+					Action: ProjectAccessDeny,
+					Rules:  []ProjectAccessRule{{Projects: []string{"*"}, Users: []string{"alice"}}},
+				}
+			},
+			expectCreated:  true,
+			expectedStatus: metrics.StatusMutated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.CreateProjects = tt.createProjects
+			cfg.DryRun = tt.dryRun
+			if tt.configure != nil {
+				tt.configure(&cfg)
+			}
+			client := &mockRancherClient{clusterID: "c-m-abc123", projectErr: tt.projectErr, createErr: tt.createErr}
+			handler := NewHandler(client, logger, cfg)
+
+			review := createAdmissionReview(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "payments-api",
+					Labels: map[string]string{"project": tt.label},
+				},
+			}, admissionv1.Create)
+			review.Request.UserInfo.Username = "alice"
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if response.Allowed == tt.expectDenied {
+				t.Fatalf("expected denied=%v, got allowed=%v: %+v", tt.expectDenied, response.Allowed, response.Result)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if created := slices.Contains(client.created, tt.label); created != tt.expectCreated {
+				t.Errorf("expected created=%v, got projects created: %v", tt.expectCreated, client.created)
+			}
+			if tt.expectCreated && response.AuditAnnotations[auditAnnotation] != "c-m-abc123:p-new01" {
+				t.Errorf("expected annotation for the created project, got %q", response.AuditAnnotations[auditAnnotation])
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseClusterDefaultProjects parses per-cluster default projects of the form
+// "cluster-a=sandbox,cluster-b=p-abc12"
+func ParseClusterDefaultProjects(s string) (map[string]string, error) {
+	projects := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		clusterName, project, found := strings.Cut(pair, "=")
+		clusterName = strings.TrimSpace(clusterName)
+		project = strings.TrimSpace(project)
+		if !found || clusterName == "" || project == "" {
+			return nil, fmt.Errorf("invalid cluster default project %q (expected cluster=project)", pair)
+		}
+		projects[clusterName] = project
+	}
+	return projects, nil
+}
+
+// defaultProjectFor returns the project unlabeled namespaces in a cluster are
+// assigned to, or "" when there is none
+func (h *Handler) defaultProjectFor(cluster clusterRef) string {
+	if project, ok := h.clusterDefaultProjects[cluster.name]; ok {
+		return project
+	}
+	return h.defaultProject
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseClusterDefaultProjects(t *testing.T) {
+	projects, err := ParseClusterDefaultProjects(" cluster-a=sandbox, cluster-b = p-abc12 ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 2 || projects["cluster-a"] != "sandbox" || projects["cluster-b"] != "p-abc12" {
+		t.Errorf("unexpected projects: %v", projects)
+	}
+
+	for _, invalid := range []string{"cluster-a", "=sandbox", "cluster-a="} {
+		if _, err := ParseClusterDefaultProjects(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestMutate_DefaultProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		cluster        string
+		annotations    map[string]string
+		expectedStatus string
+		expectedPatch  string
+	}{
+		{
+			name:           "global default",
+			cluster:        "test-cluster",
+			expectedStatus: metrics.StatusMutated,
+			expectedPatch:  `[{"op":"add","path":"/metadata/annotations","value":{}},{"op":"add","path":"/metadata/annotations/field.cattle.io~1projectId","value":"c-m-abc123:p-sandbx"}]`,
+		},
+		{
+			name:           "cluster override",
+			cluster:        "dev-cluster",
+			expectedStatus: metrics.StatusMutated,
+			expectedPatch:  `[{"op":"add","path":"/metadata/annotations","value":{}},{"op":"add","path":"/metadata/annotations/field.cattle.io~1projectId","value":"c-m-abc123:p-sandbx"}]`,
+		},
+		{
+			name:           "already in a project",
+			cluster:        "test-cluster",
+			annotations:    map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-other"},
+			expectedStatus: metrics.StatusSkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client := &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-sandbx"}
+			cfg := testHandlerConfig()
+			cfg.DefaultProject = "Default"
+			cfg.ClusterDefaultProjects = map[string]string{"dev-cluster": "sandbox"}
+			handler := NewHandler(client, logger, cfg)
+
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Annotations: tt.annotations},
+			}
+			review := createAdmissionReview(ns, admissionv1.Create)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: tt.cluster}, logger)
+
+			if !response.Allowed {
+				t.Error("expected request to be allowed")
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if string(response.Patch) != tt.expectedPatch {
+				t.Errorf("expected patch %s, got %s", tt.expectedPatch, string(response.Patch))
+			}
+		})
+	}
+}
+
+func TestDefaultProjectFor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.DefaultProject = "Default"
+	cfg.ClusterDefaultProjects = map[string]string{"dev-cluster": "sandbox", "c-m-abc123": "p-abc12"}
+	handler := NewHandler(nil, logger, cfg)
+
+	tests := map[clusterRef]string{
+		{name: "prod-cluster"}:                 "Default",
+		{name: "dev-cluster"}:                  "sandbox",
+		{name: "c-m-abc123", id: "c-m-abc123"}: "p-abc12",
+	}
+	for cluster, expected := range tests {
+		if got := handler.defaultProjectFor(cluster); got != expected {
+			t.Errorf("defaultProjectFor(%s) = %q, expected %q", cluster.name, got, expected)
+		}
+	}
+}
@@ -25,9 +25,19 @@ const (
 type RancherClient interface {
 	GetClusterID(ctx context.Context, clusterName string) (string, error)
 	GetProjectID(ctx context.Context, clusterID, projectName string) (string, error)
+	ProjectExists(ctx context.Context, clusterID, projectID string) (bool, error)
+	IsProjectMember(ctx context.Context, clusterID, projectID, username string, groups []string) (bool, error)
+	CreateProject(ctx context.Context, clusterID, displayName string) (string, error)
+	GetNamespaceDefaults(ctx context.Context, clusterID, projectID string) (resourceQuota, containerLimit string, err error)
+	GetNamespaceMetadata(ctx context.Context, clusterID, projectID string) (labels, annotations map[string]string, err error)
 	HealthCheck(ctx context.Context) error
 }
 
+// Authenticator identifies the cluster that sent a request from its credentials
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
 // HandlerConfig contains configuration options for the webhook handler
 type HandlerConfig struct {
 	StrictMode         bool
@@ -35,17 +45,58 @@ type HandlerConfig struct {
 	ProjectLabel       string
 	ProjectAnnotation  string
 	ExcludedNamespaces []string
+	// ProjectSources are checked in order for the namespace's project (default: the ProjectLabel label)
+	ProjectSources []ProjectSource
+	// TrackingProjects maps each tracking source's label to a table of label values and projects
+	TrackingProjects map[string]map[string]string
+	// Authenticator, if set, requires requests to carry credentials for the cluster in the URL path
+	Authenticator Authenticator
+	// ConflictPolicy applies when a project annotation set outside fencemaster disagrees with the label (default: label-wins)
+	ConflictPolicy ConflictPolicy
+	// LabelRemovalPolicy applies when an UPDATE removes the project label (default: keep)
+	LabelRemovalPolicy LabelRemovalPolicy
+	// DefaultProject is the project unlabeled namespaces are assigned to, and
+	// the project namespaces move to under LabelRemovalDefault
+	DefaultProject string
+	// ClusterDefaultProjects overrides DefaultProject per cluster (keyed by the cluster in the URL path)
+	ClusterDefaultProjects map[string]string
+	// ProjectRules derive the project of unlabeled namespaces from their name, before DefaultProject
+	ProjectRules []ProjectRule
+	// ProjectAccess, if set, restricts which projects the requesting user may assign namespaces to
+	ProjectAccess *ProjectAccessPolicy
+	// ProtectedProjects are project display names or IDs that project sources may not assign namespaces to
+	ProtectedProjects []string
+	// CreateProjects creates projects that don't exist yet instead of leaving the namespace unassigned
+	CreateProjects bool
 }
 
 type Handler struct {
 	rancherClient      RancherClient
+	authenticator      Authenticator
 	logger             *slog.Logger
 	strictMode         bool
 	dryRun             bool
-	projectLabel       string
+	projectSources     []ProjectSource
+	trackingProjects   map[string]map[string]string
 	projectAnnotation  string
 	excludedNamespaces map[string]struct{}
 	excludedPrefixes   []string
+	conflictPolicy     ConflictPolicy
+	labelRemovalPolicy LabelRemovalPolicy
+	defaultProject     string
+	// clusterDefaultProjects overrides defaultProject per cluster
+	clusterDefaultProjects map[string]string
+	projectRules           []ProjectRule
+	projectAccess          *ProjectAccessPolicy
+	protectedProjects      map[string]struct{}
+	createProjects         bool
+	// reviewing is set on the copies of the handler that review existing
+	// namespaces, which aren't admission requests: admission metrics are left
+	// alone and annotations already on the namespace are never undone
+	reviewing bool
+	// reportOnly is set on reviews whose patch won't be applied: missing
+	// projects are reported instead of created
+	reportOnly bool
 }
 
 func NewHandler(rancherClient RancherClient, logger *slog.Logger, cfg HandlerConfig) *Handler {
@@ -62,15 +113,45 @@ func NewHandler(rancherClient RancherClient, logger *slog.Logger, cfg HandlerCon
 		}
 	}
 
+	protected := make(map[string]struct{})
+	for _, project := range cfg.ProtectedProjects {
+		protected[project] = struct{}{}
+	}
+
+	projectSources := cfg.ProjectSources
+	if len(projectSources) == 0 {
+		projectSources = []ProjectSource{{Kind: ProjectSourceLabel, Key: cfg.ProjectLabel}}
+	}
+
+	conflictPolicy := cfg.ConflictPolicy
+	if conflictPolicy == "" {
+		conflictPolicy = ConflictLabelWins
+	}
+
+	labelRemovalPolicy := cfg.LabelRemovalPolicy
+	if labelRemovalPolicy == "" {
+		labelRemovalPolicy = LabelRemovalKeep
+	}
+
 	return &Handler{
-		rancherClient:      rancherClient,
-		logger:             logger,
-		strictMode:         cfg.StrictMode,
-		dryRun:             cfg.DryRun,
-		projectLabel:       cfg.ProjectLabel,
-		projectAnnotation:  cfg.ProjectAnnotation,
-		excludedNamespaces: excluded,
-		excludedPrefixes:   prefixes,
+		rancherClient:          rancherClient,
+		authenticator:          cfg.Authenticator,
+		logger:                 logger,
+		strictMode:             cfg.StrictMode,
+		dryRun:                 cfg.DryRun,
+		projectSources:         projectSources,
+		trackingProjects:       cfg.TrackingProjects,
+		projectAnnotation:      cfg.ProjectAnnotation,
+		excludedNamespaces:     excluded,
+		excludedPrefixes:       prefixes,
+		conflictPolicy:         conflictPolicy,
+		labelRemovalPolicy:     labelRemovalPolicy,
+		defaultProject:         cfg.DefaultProject,
+		clusterDefaultProjects: cfg.ClusterDefaultProjects,
+		projectRules:           cfg.ProjectRules,
+		projectAccess:          cfg.ProjectAccess,
+		protectedProjects:      protected,
+		createProjects:         cfg.CreateProjects,
 	}
 }
 
@@ -92,18 +173,57 @@ func (h *Handler) isNamespaceExcluded(name string) bool {
 	return false
 }
 
-// HandleMutate handles admission requests at /mutate/{cluster-name}
-func (h *Handler) HandleMutate(w http.ResponseWriter, r *http.Request) {
-	start := time.Now()
+// clusterRef identifies the downstream cluster an admission request comes from
+type clusterRef struct {
+	// name is the cluster name from the URL path, or the ID for /mutate/id/{cluster-id}
+	name string
+	// id is the management cluster ID when the URL path carries it, which skips the cluster lookup
+	id string
+}
+
+// parseClusterRef extracts the cluster from {prefix}{cluster-name} or {prefix}id/{cluster-id}
+func parseClusterRef(path, prefix string) (clusterRef, bool) {
+	path = strings.TrimPrefix(path, prefix)
+
+	if id, ok := strings.CutPrefix(path, "id/"); ok {
+		id = strings.TrimSuffix(id, "/")
+		if id == "" || strings.Contains(id, "/") {
+			return clusterRef{}, false
+		}
+		return clusterRef{name: id, id: id}, true
+	}
 
-	// Extract cluster name from URL path: /mutate/{cluster-name}
-	path := strings.TrimPrefix(r.URL.Path, "/mutate/")
 	clusterName := strings.TrimSuffix(path, "/")
+	if clusterName == "" || clusterName == strings.Trim(prefix, "/") {
+		return clusterRef{}, false
+	}
+	return clusterRef{name: clusterName}, true
+}
+
+// reviewFunc answers an admission request from a cluster. It returns the
+// response and the status recorded in request metrics.
+type reviewFunc func(ctx context.Context, req *admissionv1.AdmissionRequest, cluster clusterRef, logger *slog.Logger) (*admissionv1.AdmissionResponse, string)
+
+// HandleMutate handles admission requests at /mutate/{cluster-name} and /mutate/id/{cluster-id}
+func (h *Handler) HandleMutate(w http.ResponseWriter, r *http.Request) {
+	h.serveAdmission(w, r, "/mutate/", h.mutate)
+}
+
+// serveAdmission decodes the AdmissionReview sent to {prefix}{cluster-name} or
+// {prefix}id/{cluster-id}, answers it with review and writes the response
+func (h *Handler) serveAdmission(w http.ResponseWriter, r *http.Request, prefix string, review reviewFunc) {
+	start := time.Now()
 
-	if clusterName == "" || clusterName == "mutate" {
+	// Extract cluster from URL path: {prefix}{cluster-name} or {prefix}id/{cluster-id}
+	cluster, ok := parseClusterRef(r.URL.Path, prefix)
+	if !ok {
 		h.logger.Error("No cluster name in URL path", slog.String("path", r.URL.Path))
 		metrics.RequestsTotal.WithLabelValues("unknown", metrics.StatusError).Inc()
-		http.Error(w, "cluster name required in URL path: /mutate/{cluster-name}", http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf("cluster name required in URL path: %s{cluster-name} or %sid/{cluster-id}", prefix, prefix), http.StatusBadRequest)
+		return
+	}
+
+	if !h.authorizeCluster(w, r, cluster) {
 		return
 	}
 
@@ -128,7 +248,7 @@ func (h *Handler) HandleMutate(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger.With(slog.String("request_id", requestID))
 
 	operation := string(admissionReview.Request.Operation)
-	response, status := h.mutate(r.Context(), admissionReview.Request, clusterName, logger)
+	response, status := review(r.Context(), admissionReview.Request, cluster, logger)
 	admissionReview.Response = response
 	admissionReview.Response.UID = admissionReview.Request.UID
 
@@ -147,7 +267,71 @@ func (h *Handler) HandleMutate(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(respBytes)
 }
 
-func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest, clusterName string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+// authorizeCluster verifies that the request's credentials belong to cluster,
+// so a downstream cluster cannot obtain another cluster's project IDs by
+// changing the URL path. It writes the error response and returns false when
+// the request must be rejected.
+func (h *Handler) authorizeCluster(w http.ResponseWriter, r *http.Request, cluster clusterRef) bool {
+	if h.authenticator == nil {
+		return true
+	}
+
+	clusterName := cluster.name
+
+	identity, err := h.authenticator.Authenticate(r)
+	if err != nil {
+		h.logger.Warn("Rejecting unauthenticated request",
+			slog.String("cluster", clusterName),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("error", err.Error()),
+		)
+		metrics.AuthFailuresTotal.WithLabelValues(metrics.AuthFailureUnauthenticated).Inc()
+		metrics.RequestsTotal.WithLabelValues("unknown", metrics.StatusDenied).Inc()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	if !h.identityMatches(r.Context(), identity, cluster) {
+		h.logger.Warn("Rejecting request for a cluster other than the authenticated one",
+			slog.String("cluster", clusterName),
+			slog.String("authenticated_cluster", identity),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+		metrics.AuthFailuresTotal.WithLabelValues(metrics.AuthFailureClusterMismatch).Inc()
+		metrics.RequestsTotal.WithLabelValues("unknown", metrics.StatusDenied).Inc()
+		http.Error(w, fmt.Sprintf("credentials are not valid for cluster %s", clusterName), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// identityMatches reports whether an authenticated cluster may send requests for
+// cluster. On /mutate/id/{cluster-id} the identity is either the ID itself or a
+// cluster name that resolves to it.
+func (h *Handler) identityMatches(ctx context.Context, identity string, cluster clusterRef) bool {
+	if identity == cluster.name {
+		return true
+	}
+	if cluster.id == "" {
+		return false
+	}
+
+	clusterID, err := h.rancherClient.GetClusterID(ctx, identity)
+	return err == nil && clusterID == cluster.id
+}
+
+// clusterID returns the management cluster ID, looking it up only when the URL path didn't carry it
+func (h *Handler) clusterID(ctx context.Context, cluster clusterRef) (string, error) {
+	if cluster.id != "" {
+		return cluster.id, nil
+	}
+	return h.rancherClient.GetClusterID(ctx, cluster.name)
+}
+
+func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest, cluster clusterRef, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	clusterName := cluster.name
+
 	if req.Kind.Kind != "Namespace" {
 		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
 	}
@@ -172,9 +356,26 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
 	}
 
-	// Get the project label from the new namespace
-	projectName, hasProjectLabel := namespace.Labels[h.projectLabel]
-	if !hasProjectLabel {
+	// Get the project from the first project source the new namespace has
+	projectName, source := h.projectFromSources(&namespace)
+	if h.projectLabelRemoved(req, &namespace) {
+		switch h.labelRemovalPolicy {
+		case LabelRemovalRemove:
+			return h.removeProjectAnnotation(req, &namespace, clusterName, logger)
+		case LabelRemovalDefault:
+			defaultProject := h.defaultProjectFor(cluster)
+			logger.Info("Project label removed, moving namespace to the default project",
+				slog.String("namespace", namespace.Name),
+				slog.String("cluster", clusterName),
+				slog.String("project", defaultProject),
+			)
+			projectName, source = defaultProject, metrics.ProjectSourceDefault
+		}
+	} else if projectName == "" && namespace.Annotations[h.projectAnnotation] == "" {
+		// Namespaces already in a project (e.g. moved in the Rancher UI) are left alone
+		projectName, source = h.projectFromName(namespace.Name, cluster, logger)
+	}
+	if projectName == "" {
 		logger.Debug("Namespace has no project label, skipping",
 			slog.String("namespace", namespace.Name),
 			slog.String("cluster", clusterName),
@@ -183,31 +384,44 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
 	}
 
-	// For UPDATE operations, check if we need to do anything
-	if req.Operation == admissionv1.Update {
-		// Check current annotation value
+	// For UPDATE operations, parse the old object to see if the project label changed
+	var oldNamespace *corev1.Namespace
+	if req.Operation == admissionv1.Update && req.OldObject.Raw != nil {
+		var old corev1.Namespace
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err == nil {
+			oldNamespace = &old
+		}
+	}
+	if oldNamespace != nil {
 		currentAnnotation := namespace.Annotations[h.projectAnnotation]
-
-		// Parse old object to see if project label changed
-		var oldNamespace corev1.Namespace
-		if req.OldObject.Raw != nil {
-			if err := json.Unmarshal(req.OldObject.Raw, &oldNamespace); err == nil {
-				oldProjectName := oldNamespace.Labels[h.projectLabel]
-
-				// If project label hasn't changed and annotation exists, skip
-				if oldProjectName == projectName && currentAnnotation != "" {
-					logger.Debug("Project label unchanged and annotation exists, skipping",
-						slog.String("namespace", namespace.Name),
-						slog.String("cluster", clusterName),
-						slog.String("project", projectName),
-					)
-					return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
-				}
+		oldProjectName, _ := h.projectFromSources(oldNamespace)
+
+		// If neither the project label nor the annotation changed, the namespace
+		// stays in its project, whose defaults may have changed since
+		if oldProjectName == projectName && currentAnnotation != "" && currentAnnotation == oldNamespace.Annotations[h.projectAnnotation] {
+			clusterID, projectID, ok := strings.Cut(currentAnnotation, ":")
+			if !ok {
+				logger.Debug("Project label unchanged and annotation exists, skipping",
+					slog.String("namespace", namespace.Name),
+					slog.String("cluster", clusterName),
+					slog.String("project", projectName),
+				)
+				return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
 			}
+			return h.assignProject(ctx, req, &namespace, oldNamespace, clusterName, clusterID, projectName, source, projectID, logger)
 		}
 	}
 
-	clusterID, err := h.rancherClient.GetClusterID(ctx, clusterName)
+	logger.Debug("Resolved project source",
+		slog.String("namespace", namespace.Name),
+		slog.String("project", projectName),
+		slog.String("source", source),
+	)
+	if !h.reviewing {
+		metrics.ProjectSourcesTotal.WithLabelValues(source).Inc()
+	}
+
+	clusterID, err := h.clusterID(ctx, cluster)
 	if err != nil {
 		logger.Error("Failed to get cluster ID",
 			slog.String("cluster", clusterName),
@@ -225,15 +439,53 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 		logger.Warn("Allowing namespace without project annotation (strict mode disabled)",
 			slog.String("namespace", namespace.Name),
 			slog.String("cluster", clusterName),
-			slog.String("reason", "cluster_not_found"),
+			slog.String("reason", reasonClusterNotFound),
 		)
-		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusAllowed
+		return notAssigned(reasonClusterNotFound, projectName,
+			fmt.Sprintf("%v; namespace %s was not assigned to project '%s'", err, namespace.Name, projectName)), metrics.StatusAllowed
 	}
 
-	projectID, err := h.rancherClient.GetProjectID(ctx, clusterID, projectName)
+	// Projects from name rules and defaults are the administrator's; only
+	// project sources set on the namespace are checked against the
+	// protected projects and the project access policy
+	fromNamespace := source != metrics.ProjectSourceRule && source != metrics.ProjectSourceDefault
+
+	projectID, err := h.resolveProjectID(ctx, clusterID, projectName)
+	created := false
+	if err != nil && h.shouldCreateProject(projectName, err) {
+		// The project source is checked by display name before anything is created
+		if fromNamespace {
+			if response, status := h.checkProjectSource(ctx, req, &namespace, oldNamespace, clusterName, clusterID, projectName, source, "", logger); response != nil {
+				return response, status
+			}
+		}
+		if h.reportOnly {
+			return &admissionv1.AdmissionResponse{
+				Allowed: true,
+				AuditAnnotations: map[string]string{
+					auditDecision: decisionWouldCreate,
+					auditProject:  projectName,
+				},
+			}, metrics.StatusDryRun
+		}
+		if h.dryRun {
+			logger.Info("[DRY-RUN] Would create missing project and add project annotation to namespace",
+				slog.String("namespace", namespace.Name),
+				slog.String("cluster", clusterName),
+				slog.String("cluster_id", clusterID),
+				slog.String("project", projectName),
+				slog.String("source", source),
+				slog.String("operation", string(req.Operation)),
+			)
+			return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusDryRun
+		}
+		projectID, err = h.createProject(ctx, namespace.Name, clusterName, clusterID, projectName, source, logger)
+		created = err == nil
+	}
 	if err != nil {
 		logger.Error("Failed to get project ID",
 			slog.String("project", projectName),
+			slog.String("source", source),
 			slog.String("cluster_id", clusterID),
 			slog.String("error", err.Error()),
 		)
@@ -250,21 +502,63 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 			slog.String("namespace", namespace.Name),
 			slog.String("cluster", clusterName),
 			slog.String("project", projectName),
-			slog.String("reason", "project_not_found"),
+			slog.String("reason", reasonProjectNotFound),
 		)
-		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusAllowed
+		return notAssigned(reasonProjectNotFound, projectName,
+			fmt.Sprintf("%v; namespace %s was not assigned to a project", err, namespace.Name)), metrics.StatusAllowed
 	}
 
 	projectAnnotationValue := fmt.Sprintf("%s:%s", clusterID, projectID)
 
-	// Check if annotation already has the correct value (avoid unnecessary patches)
-	if namespace.Annotations != nil && namespace.Annotations[h.projectAnnotation] == projectAnnotationValue {
-		logger.Debug("Annotation already has correct value, skipping",
+	if fromNamespace && !created {
+		if response, status := h.checkProjectSource(ctx, req, &namespace, oldNamespace, clusterName, clusterID, projectName, source, projectID, logger); response != nil {
+			return response, status
+		}
+	}
+
+	if h.annotationConflicts(&namespace, oldNamespace, projectName, projectAnnotationValue) {
+		if response, status := h.resolveConflict(&namespace, clusterName, projectName, source, projectAnnotationValue, logger); response != nil {
+			return response, status
+		}
+	}
+
+	return h.assignProject(ctx, req, &namespace, oldNamespace, clusterName, clusterID, projectName, source, projectID, logger)
+}
+
+// assignProject assigns a namespace to a project: it returns the patch
+// setting the project annotation, unless the namespace already has it, and
+// the project's quota annotations, labels and annotations the namespace
+// doesn't carry yet. A namespace that needs none of them is skipped.
+func (h *Handler) assignProject(ctx context.Context, req *admissionv1.AdmissionRequest, namespace, oldNamespace *corev1.Namespace, clusterName, clusterID, projectName, source, projectID string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	projectAnnotationValue := fmt.Sprintf("%s:%s", clusterID, projectID)
+
+	// Projects with quotas expect their namespaces to carry the quota annotations
+	var warnings []string
+	var annotations []annotation
+	if namespace.Annotations[h.projectAnnotation] != projectAnnotationValue {
+		annotations = append(annotations, annotation{key: h.projectAnnotation, value: projectAnnotationValue})
+	}
+	defaults, staleDefaults, err := h.namespaceDefaultAnnotations(ctx, namespace, oldNamespace, clusterName, clusterID, projectID, logger)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	annotations = append(annotations, defaults...)
+
+	// Projects can also prescribe labels and annotations for their namespaces
+	labels, extra, err := h.namespaceMetadata(ctx, namespace, clusterName, clusterID, projectID, logger)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	annotations = append(annotations, extra...)
+
+	// Check if the namespace already has everything (avoid unnecessary patches)
+	if len(annotations) == 0 && len(staleDefaults) == 0 && len(labels) == 0 {
+		logger.Debug("Namespace already in its project with the project's defaults, skipping",
 			slog.String("namespace", namespace.Name),
 			slog.String("cluster", clusterName),
 			slog.String("annotation", projectAnnotationValue),
 		)
-		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}, metrics.StatusSkipped
 	}
 
 	// Dry-run mode: log what would happen but don't apply the patch
@@ -274,35 +568,54 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 			slog.String("cluster", clusterName),
 			slog.String("cluster_id", clusterID),
 			slog.String("project", projectName),
+			slog.String("source", source),
 			slog.String("project_id", projectID),
 			slog.String("annotation", projectAnnotationValue),
+			slog.Int("default_annotations", len(defaults)),
+			slog.Int("removed_default_annotations", len(staleDefaults)),
+			slog.Int("project_labels", len(labels)),
+			slog.Int("project_annotations", len(extra)),
 			slog.String("operation", string(req.Operation)),
 		)
-		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusDryRun
+		return &admissionv1.AdmissionResponse{Allowed: true, Warnings: warnings}, metrics.StatusDryRun
 	}
 
-	patch := []map[string]any{
-		{
+	var patch []map[string]any
+
+	// If annotations don't exist yet, create the map before adding to it
+	if namespace.Annotations == nil {
+		patch = append(patch, map[string]any{
 			"op":    "add",
 			"path":  "/metadata/annotations",
 			"value": map[string]string{},
-		},
-		{
+		})
+	}
+	for _, a := range annotations {
+		patch = append(patch, map[string]any{
 			"op":    "add",
-			"path":  fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(h.projectAnnotation)),
-			"value": projectAnnotationValue,
-		},
+			"path":  fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(a.key)),
+			"value": a.value,
+		})
 	}
-
-	// If annotations already exist, only add/replace the project annotation
-	if namespace.Annotations != nil {
-		patch = []map[string]any{
-			{
-				"op":    "add",
-				"path":  fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(h.projectAnnotation)),
-				"value": projectAnnotationValue,
-			},
-		}
+	for _, key := range staleDefaults {
+		patch = append(patch, map[string]any{
+			"op":   "remove",
+			"path": fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(key)),
+		})
+	}
+	if len(labels) > 0 && namespace.Labels == nil {
+		patch = append(patch, map[string]any{
+			"op":    "add",
+			"path":  "/metadata/labels",
+			"value": map[string]string{},
+		})
+	}
+	for _, l := range labels {
+		patch = append(patch, map[string]any{
+			"op":    "add",
+			"path":  fmt.Sprintf("/metadata/labels/%s", escapeJSONPointer(l.key)),
+			"value": l.value,
+		})
 	}
 
 	patchBytes, err := json.Marshal(patch)
@@ -321,8 +634,13 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 		slog.String("cluster", clusterName),
 		slog.String("cluster_id", clusterID),
 		slog.String("project", projectName),
+		slog.String("source", source),
 		slog.String("project_id", projectID),
 		slog.String("annotation", projectAnnotationValue),
+		slog.Int("default_annotations", len(defaults)),
+		slog.Int("removed_default_annotations", len(staleDefaults)),
+		slog.Int("project_labels", len(labels)),
+		slog.Int("project_annotations", len(extra)),
 		slog.String("operation", string(req.Operation)),
 	)
 
@@ -331,9 +649,48 @@ func (h *Handler) mutate(ctx context.Context, req *admissionv1.AdmissionRequest,
 		Allowed:   true,
 		Patch:     patchBytes,
 		PatchType: &patchType,
+		Warnings:  warnings,
+		AuditAnnotations: map[string]string{
+			auditDecision:   decisionAssigned,
+			auditProject:    projectName,
+			auditSource:     source,
+			auditAnnotation: projectAnnotationValue,
+		},
 	}, metrics.StatusMutated
 }
 
+// keepPreviousAssignment makes a response that admits a namespace without
+// assigning it to its project also undo a project annotation set by the
+// request itself, which would assign the namespace all the same. The
+// annotation goes back to its value before the request, or is removed.
+func (h *Handler) keepPreviousAssignment(response *admissionv1.AdmissionResponse, namespace, oldNamespace *corev1.Namespace) *admissionv1.AdmissionResponse {
+	current, ok := namespace.Annotations[h.projectAnnotation]
+	if !response.Allowed || !ok || h.dryRun || h.reviewing {
+		return response
+	}
+
+	op := map[string]any{
+		"op":   "remove",
+		"path": fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(h.projectAnnotation)),
+	}
+	if oldNamespace != nil {
+		if previous, ok := oldNamespace.Annotations[h.projectAnnotation]; ok {
+			if previous == current {
+				return response
+			}
+			op["op"], op["value"] = "replace", previous
+		}
+	}
+
+	patchBytes, err := json.Marshal([]map[string]any{op})
+	if err != nil {
+		return response
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	response.Patch, response.PatchType = patchBytes, &patchType
+	return response
+}
+
 // jsonPointerReplacer escapes strings for JSON Pointer (RFC 6901)
 // ~ becomes ~0, / becomes ~1 (order matters: ~ must be replaced first)
 var jsonPointerReplacer = strings.NewReplacer("~", "~0", "/", "~1")
@@ -9,6 +9,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/rvbsalgado/fencemaster/pkg/metrics"
@@ -56,7 +58,7 @@ func TestMutate_NonNamespaceResource(t *testing.T) {
 		},
 	}
 
-	response, status := handler.mutate(context.Background(), req, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), req, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed for non-namespace resource")
@@ -88,7 +90,7 @@ func TestMutate_NamespaceWithoutProjectLabel(t *testing.T) {
 		Operation: admissionv1.Create,
 	}
 
-	response, status := handler.mutate(context.Background(), req, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), req, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed for namespace without project label")
@@ -112,7 +114,7 @@ func TestMutate_InvalidNamespaceJSON(t *testing.T) {
 		Operation: admissionv1.Create,
 	}
 
-	response, status := handler.mutate(context.Background(), req, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), req, clusterRef{name: "test-cluster"}, logger)
 
 	if response.Allowed {
 		t.Error("expected request to be denied for invalid namespace JSON")
@@ -240,12 +242,44 @@ func createAdmissionReviewWithOld(ns, oldNs *corev1.Namespace, operation admissi
 	}
 }
 
+// newTestNamespace returns the namespace test-ns with the given project label
+// and project annotation, each left out when empty. Project platform is
+// c-m-abc123:p-xyz789 in the mock client.
+func newTestNamespace(project, annotation string) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+	if project != "" {
+		ns.Labels = map[string]string{"project": project}
+	}
+	if annotation != "" {
+		ns.Annotations = map[string]string{"field.cattle.io/projectId": annotation}
+	}
+	return ns
+}
+
 // mockRancherClient implements a mock for testing the full mutation flow
 type mockRancherClient struct {
 	clusterID   string
 	clusterErr  error
 	projectID   string
 	projectErr  error
+	// existingProjects are the project IDs ProjectExists reports as present
+	existingProjects []string
+	// members are the usernames and groups IsProjectMember reports as members
+	members   []string
+	memberErr error
+	// created are the display names CreateProject was called with
+	created   []string
+	createErr error
+	// resourceQuota and containerLimit are the namespace defaults GetNamespaceDefaults returns
+	resourceQuota  string
+	containerLimit string
+	defaultsErr    error
+	// defaultsByProject overrides the namespace defaults of some project IDs
+	defaultsByProject map[string]mockDefaults
+	// labels and annotations are the namespace metadata GetNamespaceMetadata returns
+	labels      map[string]string
+	annotations map[string]string
+	metadataErr error
 }
 
 func (m *mockRancherClient) GetClusterID(ctx context.Context, clusterName string) (string, error) {
@@ -262,6 +296,50 @@ func (m *mockRancherClient) GetProjectID(ctx context.Context, clusterID, project
 	return m.projectID, nil
 }
 
+func (m *mockRancherClient) ProjectExists(ctx context.Context, clusterID, projectID string) (bool, error) {
+	return slices.Contains(m.existingProjects, projectID), nil
+}
+
+func (m *mockRancherClient) IsProjectMember(ctx context.Context, clusterID, projectID, username string, groups []string) (bool, error) {
+	if m.memberErr != nil {
+		return false, m.memberErr
+	}
+	if slices.Contains(m.members, username) {
+		return true, nil
+	}
+	for _, group := range groups {
+		if slices.Contains(m.members, group) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRancherClient) CreateProject(ctx context.Context, clusterID, displayName string) (string, error) {
+	if m.createErr != nil {
+		return "", m.createErr
+	}
+	m.created = append(m.created, displayName)
+	return "p-new01", nil
+}
+
+// mockDefaults are the namespace defaults of a project
+type mockDefaults struct {
+	resourceQuota  string
+	containerLimit string
+}
+
+func (m *mockRancherClient) GetNamespaceDefaults(ctx context.Context, clusterID, projectID string) (string, string, error) {
+	if defaults, ok := m.defaultsByProject[projectID]; ok {
+		return defaults.resourceQuota, defaults.containerLimit, nil
+	}
+	return m.resourceQuota, m.containerLimit, m.defaultsErr
+}
+
+func (m *mockRancherClient) GetNamespaceMetadata(ctx context.Context, clusterID, projectID string) (map[string]string, map[string]string, error) {
+	return m.labels, m.annotations, m.metadataErr
+}
+
 func (m *mockRancherClient) HealthCheck(ctx context.Context) error {
 	return nil
 }
@@ -415,7 +493,7 @@ func TestMutate_ExcludedNamespace(t *testing.T) {
 			}
 
 			review := createAdmissionReview(ns, admissionv1.Create)
-			response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 			if !response.Allowed {
 				t.Error("expected request to be allowed")
@@ -446,7 +524,7 @@ func TestMutate_SuccessfulMutation(t *testing.T) {
 	}
 
 	review := createAdmissionReview(ns, admissionv1.Create)
-	response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed")
@@ -498,7 +576,7 @@ func TestMutate_DryRunMode(t *testing.T) {
 	}
 
 	review := createAdmissionReview(ns, admissionv1.Create)
-	response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed in dry-run mode")
@@ -531,7 +609,7 @@ func TestMutate_StrictModeClusterNotFound(t *testing.T) {
 	}
 
 	review := createAdmissionReview(ns, admissionv1.Create)
-	response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 	if response.Allowed {
 		t.Error("expected request to be denied in strict mode when cluster not found")
@@ -559,7 +637,7 @@ func TestMutate_PermissiveModeClusterNotFound(t *testing.T) {
 	}
 
 	review := createAdmissionReview(ns, admissionv1.Create)
-	response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed in permissive mode")
@@ -603,7 +681,7 @@ func TestMutate_UpdateWithUnchangedLabel(t *testing.T) {
 	}
 
 	review := createAdmissionReviewWithOld(ns, oldNs, admissionv1.Update)
-	response, status := handler.mutate(context.Background(), review.Request, "test-cluster", logger)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
 
 	if !response.Allowed {
 		t.Error("expected request to be allowed")
@@ -612,3 +690,183 @@ func TestMutate_UpdateWithUnchangedLabel(t *testing.T) {
 		t.Errorf("expected status '%s', got '%s'", metrics.StatusSkipped, status)
 	}
 }
+
+// mockAuthenticator authenticates every request as a fixed cluster
+type mockAuthenticator struct {
+	cluster string
+	err     error
+}
+
+func (m *mockAuthenticator) Authenticate(r *http.Request) (string, error) {
+	return m.cluster, m.err
+}
+
+func TestHandleMutate_Authentication(t *testing.T) {
+	tests := []struct {
+		name          string
+		authenticator Authenticator
+		path          string
+		expectedCode  int
+	}{
+		{
+			name:         "no authenticator configured",
+			path:         "/mutate/cluster-a",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "credentials match cluster",
+			authenticator: &mockAuthenticator{cluster: "cluster-a"},
+			path:          "/mutate/cluster-a",
+			expectedCode:  http.StatusOK,
+		},
+		{
+			name:          "credentials for another cluster",
+			authenticator: &mockAuthenticator{cluster: "cluster-b"},
+			path:          "/mutate/cluster-a",
+			expectedCode:  http.StatusForbidden,
+		},
+		{
+			name:          "missing credentials",
+			authenticator: &mockAuthenticator{err: fmt.Errorf("no client credentials provided")},
+			path:          "/mutate/cluster-a",
+			expectedCode:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.Authenticator = tt.authenticator
+			handler := NewHandler(nil, logger, cfg)
+
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-ns",
+				},
+			}
+			body, _ := json.Marshal(createAdmissionReview(ns, admissionv1.Create))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.HandleMutate(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestParseClusterRef(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected clusterRef
+		ok       bool
+	}{
+		{"/mutate/cluster-a", clusterRef{name: "cluster-a"}, true},
+		{"/mutate/cluster-a/", clusterRef{name: "cluster-a"}, true},
+		{"/mutate/id/c-m-abc123", clusterRef{name: "c-m-abc123", id: "c-m-abc123"}, true},
+		{"/mutate/id/c-m-abc123/", clusterRef{name: "c-m-abc123", id: "c-m-abc123"}, true},
+		{"/mutate/id/", clusterRef{}, false},
+		{"/mutate/id/c-m-abc123/extra", clusterRef{}, false},
+		{"/mutate/", clusterRef{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			cluster, ok := parseClusterRef(tt.path, "/mutate/")
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if cluster != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, cluster)
+			}
+		})
+	}
+}
+
+func TestMutate_ClusterIDInPathSkipsLookup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mockClient := &mockRancherClient{
+		clusterErr: fmt.Errorf("cluster lookup must not be called"),
+		projectID:  "p-xyz789",
+	}
+
+	cfg := testHandlerConfig()
+	cfg.StrictMode = true
+	handler := NewHandler(mockClient, logger, cfg)
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-ns",
+			Labels: map[string]string{
+				"project": "platform",
+			},
+		},
+	}
+
+	review := createAdmissionReview(ns, admissionv1.Create)
+	response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "c-m-abc123", id: "c-m-abc123"}, logger)
+
+	if status != metrics.StatusMutated {
+		t.Fatalf("expected status '%s', got '%s'", metrics.StatusMutated, status)
+	}
+	if !strings.Contains(string(response.Patch), "c-m-abc123:p-xyz789") {
+		t.Errorf("expected patch to contain annotation value 'c-m-abc123:p-xyz789', got: %s", string(response.Patch))
+	}
+}
+
+func TestHandleMutate_AuthenticationWithClusterID(t *testing.T) {
+	tests := []struct {
+		name         string
+		identity     string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "identity is the cluster ID",
+			identity:     "c-m-abc123",
+			path:         "/mutate/id/c-m-abc123",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "identity resolves to the cluster ID",
+			identity:     "cluster-a",
+			path:         "/mutate/id/c-m-abc123",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "identity resolves to another cluster ID",
+			identity:     "cluster-a",
+			path:         "/mutate/id/c-m-other",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.Authenticator = &mockAuthenticator{cluster: tt.identity}
+			handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123"}, logger, cfg)
+
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-ns",
+				},
+			}
+			body, _ := json.Marshal(createAdmissionReview(ns, admissionv1.Create))
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.HandleMutate(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// namespaceMetadata returns the labels and annotations a project sets for its
+// namespaces (e.g. pod-security.kubernetes.io/enforce or cost-center) that
+// the namespace doesn't already carry with the same value. The project is the
+// source of truth, so its values replace the namespace's, except for the keys
+// fencemaster assigns projects by. A failed lookup is returned as an error for
+// the response warning; the namespace is still assigned.
+func (h *Handler) namespaceMetadata(ctx context.Context, namespace *corev1.Namespace, clusterName, clusterID, projectID string, logger *slog.Logger) ([]annotation, []annotation, error) {
+	labels, annotations, err := h.rancherClient.GetNamespaceMetadata(ctx, clusterID, projectID)
+	if err != nil {
+		logger.Warn("Failed to get project namespace metadata, assigning namespace without project labels and annotations",
+			slog.String("namespace", namespace.Name),
+			slog.String("cluster", clusterName),
+			slog.String("cluster_id", clusterID),
+			slog.String("project_id", projectID),
+			slog.String("error", err.Error()),
+		)
+		return nil, nil, fmt.Errorf("%v; namespace %s was assigned without the project's labels and annotations", err, namespace.Name)
+	}
+
+	reservedLabels := make(map[string]struct{})
+	reservedAnnotations := map[string]struct{}{
+		h.projectAnnotation:      {},
+		resourceQuotaAnnotation:  {},
+		containerLimitAnnotation: {},
+	}
+	for _, source := range h.projectSources {
+		if source.Kind == ProjectSourceAnnotation {
+			reservedAnnotations[source.Key] = struct{}{}
+		} else {
+			reservedLabels[source.Key] = struct{}{}
+		}
+	}
+
+	return metadataChanges(labels, namespace.Labels, reservedLabels),
+		metadataChanges(annotations, namespace.Annotations, reservedAnnotations), nil
+}
+
+// metadataChanges returns the entries of want, sorted by key for a stable
+// patch, that aren't reserved and differ from the namespace's current ones
+func metadataChanges(want, current map[string]string, reserved map[string]struct{}) []annotation {
+	var changes []annotation
+	for _, key := range slices.Sorted(maps.Keys(want)) {
+		if _, ok := reserved[key]; ok {
+			continue
+		}
+		if value, ok := current[key]; ok && value == want[key] {
+			continue
+		}
+		changes = append(changes, annotation{key: key, value: want[key]})
+	}
+	return changes
+}
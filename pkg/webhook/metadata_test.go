@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMutate_NamespaceMetadata(t *testing.T) {
+	tests := []struct {
+		name                string
+		client              *mockRancherClient
+		labels              map[string]string
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+		expectWarning       bool
+	}{
+		{
+			name: "project labels and annotations",
+			client: &mockRancherClient{
+				labels:      map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+				annotations: map[string]string{"owner": "payments@example.com"},
+			},
+			expectedLabels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"},
+			expectedAnnotations: map[string]string{
+				"field.cattle.io/projectId": "c-m-abc123:p-xyz789",
+				"owner":                     "payments@example.com",
+			},
+		},
+		{
+			name:   "project values replace the namespace's",
+			client: &mockRancherClient{labels: map[string]string{"cost-center": "cc-1234", "team": "payments"}},
+			labels: map[string]string{"cost-center": "cc-0000", "team": "payments"},
+			expectedLabels: map[string]string{
+				"cost-center": "cc-1234",
+			},
+			expectedAnnotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+		},
+		{
+			name: "keys fencemaster assigns projects by are left alone",
+			client: &mockRancherClient{
+				labels:      map[string]string{"project": "sandbox"},
+				annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-other"},
+			},
+			expectedAnnotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+		},
+		{
+			name:                "lookup fails",
+			client:              &mockRancherClient{metadataErr: errors.New("connection refused")},
+			expectedAnnotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+			expectWarning:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			tt.client.clusterID, tt.client.projectID = "c-m-abc123", "p-xyz789"
+			handler := NewHandler(tt.client, logger, testHandlerConfig())
+
+			labels := map[string]string{"project": "platform"}
+			maps.Copy(labels, tt.labels)
+			review := createAdmissionReview(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: labels},
+			}, admissionv1.Create)
+			response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			var patch []map[string]any
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("failed to unmarshal patch: %v", err)
+			}
+			addedLabels := make(map[string]string)
+			addedAnnotations := make(map[string]string)
+			for _, op := range patch {
+				path := op["path"].(string)
+				if key, ok := strings.CutPrefix(path, "/metadata/labels/"); ok {
+					addedLabels[strings.ReplaceAll(key, "~1", "/")] = op["value"].(string)
+				}
+				if key, ok := strings.CutPrefix(path, "/metadata/annotations/"); ok {
+					addedAnnotations[strings.ReplaceAll(key, "~1", "/")] = op["value"].(string)
+				}
+				if path == "/metadata/labels" {
+					t.Errorf("expected the existing labels map to be reused, got %v", op)
+				}
+			}
+			if !maps.Equal(addedLabels, tt.expectedLabels) {
+				t.Errorf("expected labels %v, got %v", tt.expectedLabels, addedLabels)
+			}
+			if !maps.Equal(addedAnnotations, tt.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tt.expectedAnnotations, addedAnnotations)
+			}
+
+			if hasWarning := len(response.Warnings) > 0; hasWarning != tt.expectWarning {
+				t.Errorf("expected warning=%v, got %q", tt.expectWarning, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestMutate_NamespaceMetadataPropagatesProjectChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := &mockRancherClient{
+		clusterID: "c-m-abc123",
+		projectID: "p-xyz789",
+		labels:    map[string]string{"cost-center": "cc-1234"},
+	}
+	handler := NewHandler(client, logger, testHandlerConfig())
+
+	// The namespace was assigned when the project set cost-center=cc-1234
+	assigned := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ns",
+			Labels:      map[string]string{"project": "platform", "cost-center": "cc-1234"},
+			Annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+		},
+	}
+	review := createAdmissionReviewWithOld(assigned, assigned, admissionv1.Update)
+	if response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger); status != metrics.StatusSkipped || response.Patch != nil {
+		t.Fatalf("expected namespace in sync with its project to be skipped, got status '%s' and patch %s", status, response.Patch)
+	}
+
+	// The project's labels are then edited
+	client.labels = map[string]string{"cost-center": "cc-5678", "tier": "gold"}
+	for _, operation := range []admissionv1.Operation{admissionv1.Update, admissionv1.Create} {
+		review := createAdmissionReviewWithOld(assigned, assigned, operation)
+		if operation == admissionv1.Create {
+			review = createAdmissionReview(assigned, operation)
+		}
+		response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+		if status != metrics.StatusMutated {
+			t.Fatalf("%s: expected namespace to be patched, got status '%s'", operation, status)
+		}
+		expected := `[{"op":"add","path":"/metadata/labels/cost-center","value":"cc-5678"},{"op":"add","path":"/metadata/labels/tier","value":"gold"}]`
+		if string(response.Patch) != expected {
+			t.Errorf("%s: expected patch %s, got %s", operation, expected, response.Patch)
+		}
+	}
+}
+
+func TestMutate_NamespaceMetadataCreatesLabels(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := &mockRancherClient{
+		clusterID: "c-m-abc123",
+		projectID: "p-xyz789",
+		labels:    map[string]string{"cost-center": "cc-1234"},
+	}
+	cfg := testHandlerConfig()
+	cfg.ProjectSources = []ProjectSource{{Kind: ProjectSourceAnnotation, Key: "example.com/project"}}
+	handler := NewHandler(client, logger, cfg)
+
+	review := createAdmissionReview(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ns",
+			Annotations: map[string]string{"example.com/project": "platform"},
+		},
+	}, admissionv1.Create)
+	response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+	var patch []map[string]any
+	if err := json.Unmarshal(response.Patch, &patch); err != nil {
+		t.Fatalf("failed to unmarshal patch: %v", err)
+	}
+	var paths []string
+	for _, op := range patch {
+		paths = append(paths, op["path"].(string))
+	}
+	expected := []string{
+		"/metadata/annotations/field.cattle.io~1projectId",
+		"/metadata/labels",
+		"/metadata/labels/cost-center",
+	}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected patch paths %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// projectIDPattern matches Rancher project IDs such as p-x7k2m
+	projectIDPattern = regexp.MustCompile(`^p-[a-z0-9]+$`)
+	// qualifiedProjectIDPattern matches "clusterID:projectID" as used in the
+	// project annotation. Label values can't contain ":", so "_" is accepted too.
+	qualifiedProjectIDPattern = regexp.MustCompile(`^(local|c-[a-z0-9-]+)[:_](p-[a-z0-9]+)$`)
+)
+
+// resolveProjectID returns the ID of the project a label value refers to in
+// clusterID. The value is either a "clusterID:projectID" pair, which must name
+// the request's cluster, a project ID, or a project display name.
+func (h *Handler) resolveProjectID(ctx context.Context, clusterID, value string) (string, error) {
+	if m := qualifiedProjectIDPattern.FindStringSubmatch(value); m != nil {
+		if m[1] != clusterID {
+			return "", fmt.Errorf("project %s belongs to cluster %s, not to the requesting cluster %s", m[2], m[1], clusterID)
+		}
+		exists, err := h.rancherClient.ProjectExists(ctx, clusterID, m[2])
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", fmt.Errorf("project %s not found in cluster %s", m[2], clusterID)
+		}
+		return m[2], nil
+	}
+
+	if projectIDPattern.MatchString(value) {
+		exists, err := h.rancherClient.ProjectExists(ctx, clusterID, value)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return value, nil
+		}
+		// Not an ID in this cluster; display names can look like IDs too
+	}
+
+	return h.rancherClient.GetProjectID(ctx, clusterID, value)
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestResolveProjectID(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		client      *mockRancherClient
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "display name",
+			value:    "platform",
+			client:   &mockRancherClient{projectID: "p-abc12"},
+			expected: "p-abc12",
+		},
+		{
+			name:     "existing project ID",
+			value:    "p-xyz78",
+			client:   &mockRancherClient{existingProjects: []string{"p-xyz78"}, projectErr: fmt.Errorf("no display name lookup expected")},
+			expected: "p-xyz78",
+		},
+		{
+			name:     "display name that looks like a project ID",
+			value:    "p-infra",
+			client:   &mockRancherClient{projectID: "p-abc12"},
+			expected: "p-abc12",
+		},
+		{
+			name:        "unknown project ID",
+			value:       "p-gone1",
+			client:      &mockRancherClient{projectErr: fmt.Errorf("project p-gone1 not found")},
+			expectError: true,
+		},
+		{
+			name:     "cluster and project ID",
+			value:    "c-m-abc123:p-xyz78",
+			client:   &mockRancherClient{existingProjects: []string{"p-xyz78"}},
+			expected: "p-xyz78",
+		},
+		{
+			name:     "label-safe cluster and project ID",
+			value:    "c-m-abc123_p-xyz78",
+			client:   &mockRancherClient{existingProjects: []string{"p-xyz78"}},
+			expected: "p-xyz78",
+		},
+		{
+			name:        "cluster and project ID for another cluster",
+			value:       "c-m-other:p-xyz78",
+			client:      &mockRancherClient{existingProjects: []string{"p-xyz78"}},
+			expectError: true,
+		},
+		{
+			name:        "cluster and unknown project ID",
+			value:       "c-m-abc123:p-gone1",
+			client:      &mockRancherClient{projectID: "p-abc12"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := NewHandler(tt.client, logger, testHandlerConfig())
+
+			projectID, err := handler.resolveProjectID(context.Background(), "c-m-abc123", tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got project ID %q", projectID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if projectID != tt.expected {
+				t.Errorf("expected project ID %q, got %q", tt.expected, projectID)
+			}
+		})
+	}
+}
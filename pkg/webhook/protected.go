@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isProjectProtected reports whether a project is on the protected projects
+// list, by display name or project ID. A project given by ID is also
+// protected when a protected display name resolves to it in the cluster, so
+// that labeling a namespace with the System project's ID doesn't get around
+// protecting "System".
+func (h *Handler) isProjectProtected(ctx context.Context, clusterID, projectName, projectID string, logger *slog.Logger) bool {
+	if _, ok := h.protectedProjects[projectName]; ok {
+		return true
+	}
+	if _, ok := h.protectedProjects[projectID]; ok {
+		return true
+	}
+	if !projectIDPattern.MatchString(projectName) && !qualifiedProjectIDPattern.MatchString(projectName) {
+		return false
+	}
+
+	for protected := range h.protectedProjects {
+		if projectIDPattern.MatchString(protected) {
+			continue
+		}
+		id, err := h.rancherClient.GetProjectID(ctx, clusterID, protected)
+		if err != nil {
+			// Most often the protected project doesn't exist in this cluster
+			logger.Debug("Failed to resolve protected project",
+				slog.String("project", protected),
+				slog.String("cluster_id", clusterID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// refuseProtectedProject rejects a project source that targets a protected
+// project in strict mode, and otherwise admits the namespace without a
+// project annotation
+func (h *Handler) refuseProtectedProject(namespaceName, clusterName, projectName, source, projectID string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	attrs := []any{
+		slog.String("namespace", namespaceName),
+		slog.String("cluster", clusterName),
+		slog.String("project", projectName),
+		slog.String("source", source),
+		slog.String("project_id", projectID),
+	}
+
+	if h.strictMode {
+		logger.Warn("Project source targets a protected project, denying", attrs...)
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: fmt.Sprintf("project '%s' is protected: namespaces cannot be assigned to it by %s", projectName, source),
+			},
+		}, metrics.StatusDenied
+	}
+
+	logger.Warn("Project source targets a protected project, allowing namespace without project annotation (strict mode disabled)", attrs...)
+	return notAssigned(reasonProtectedProject, projectName,
+		fmt.Sprintf("project '%s' is protected; namespace %s was not assigned to it", projectName, namespaceName)), metrics.StatusSkipped
+}
@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMutate_ProtectedProject(t *testing.T) {
+	tests := []struct {
+		name           string
+		label          string
+		strictMode     bool
+		expectedStatus string
+		expectAllowed  bool
+	}{
+		{name: "protected by name, permissive", label: "System", expectedStatus: metrics.StatusSkipped, expectAllowed: true},
+		{name: "protected by name, strict", label: "System", strictMode: true, expectedStatus: metrics.StatusDenied},
+		{name: "protected name resolves to labeled ID", label: "p-sys01", strictMode: true, expectedStatus: metrics.StatusDenied},
+		{name: "protected name resolves to qualified ID", label: "c-m-abc123_p-sys01", strictMode: true, expectedStatus: metrics.StatusDenied},
+		{name: "unprotected", label: "platform", strictMode: true, expectedStatus: metrics.StatusMutated, expectAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectID := "p-xyz789"
+			if tt.label != "platform" {
+				projectID = "p-sys01"
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.StrictMode = tt.strictMode
+			cfg.ProtectedProjects = []string{"System"}
+			handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: projectID, existingProjects: []string{projectID}}, logger, cfg)
+
+			review := createAdmissionReview(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "tenant-ns",
+					Labels: map[string]string{"project": tt.label},
+				},
+			}, admissionv1.Create)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if response.Allowed != tt.expectAllowed {
+				t.Errorf("expected allowed=%v, got %v", tt.expectAllowed, response.Allowed)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if !tt.expectAllowed && !strings.Contains(response.Result.Message, "is protected") {
+				t.Errorf("expected denial for a protected project, got %q", response.Result.Message)
+			}
+			if tt.expectedStatus != metrics.StatusMutated && response.Patch != nil {
+				t.Errorf("expected no patch, got %s", response.Patch)
+			}
+		})
+	}
+}
+
+func TestMutate_ProtectedProjectInRequestAnnotation(t *testing.T) {
+	tests := []struct {
+		name          string
+		oldAnnotation string
+		expectedPatch string
+	}{
+		{
+			name:          "annotation set on create is removed",
+			expectedPatch: `[{"op":"remove","path":"/metadata/annotations/field.cattle.io~1projectId"}]`,
+		},
+		{
+			name:          "annotation changed on update is restored",
+			oldAnnotation: "c-m-abc123:p-xyz789",
+			expectedPatch: `[{"op":"replace","path":"/metadata/annotations/field.cattle.io~1projectId","value":"c-m-abc123:p-xyz789"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.ProtectedProjects = []string{"System"}
+			handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-sys01"}, logger, cfg)
+
+			// The requester labels the namespace and sets the protected project's annotation itself
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "tenant-ns",
+					Labels:      map[string]string{"project": "System"},
+					Annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-sys01"},
+				},
+			}
+			review := createAdmissionReview(ns, admissionv1.Create)
+			if tt.oldAnnotation != "" {
+				old := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:        "tenant-ns",
+					Annotations: map[string]string{"field.cattle.io/projectId": tt.oldAnnotation},
+				}}
+				review = createAdmissionReviewWithOld(ns, old, admissionv1.Update)
+			}
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if !response.Allowed || status != metrics.StatusSkipped {
+				t.Errorf("expected namespace to be admitted unassigned, got allowed=%v and status '%s'", response.Allowed, status)
+			}
+			if string(response.Patch) != tt.expectedPatch {
+				t.Errorf("expected patch %s, got %s", tt.expectedPatch, response.Patch)
+			}
+		})
+	}
+}
+
+func TestMutate_ProtectedProjectAsDefault(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.StrictMode = true
+	cfg.DefaultProject = "System"
+	cfg.ProtectedProjects = []string{"System"}
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-sys01"}, logger, cfg)
+
+	// A default project chosen by the administrator is not refused
+	review := createAdmissionReview(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "infra-ns"}}, admissionv1.Create)
+	if _, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger); status != metrics.StatusMutated {
+		t.Errorf("expected status '%s', got '%s'", metrics.StatusMutated, status)
+	}
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Namespace annotations Rancher expects on the namespaces of projects with
+// resource quotas
+const (
+	resourceQuotaAnnotation  = "field.cattle.io/resourceQuota"
+	containerLimitAnnotation = "field.cattle.io/containerDefaultResourceLimit"
+)
+
+// annotation is a namespace annotation or label to add
+type annotation struct {
+	key   string
+	value string
+}
+
+// namespaceDefaultAnnotations returns the resource quota and container limit
+// annotations of a project's namespace defaults that the namespace doesn't
+// set itself, and the keys of those to remove. Values a namespace moving
+// between projects got from its previous project's defaults aren't its own,
+// so they are replaced by the new project's, or removed when it has none. A
+// failed lookup is returned as an error for the response warning; the
+// namespace is still assigned.
+func (h *Handler) namespaceDefaultAnnotations(ctx context.Context, namespace, oldNamespace *corev1.Namespace, clusterName, clusterID, projectID string, logger *slog.Logger) ([]annotation, []string, error) {
+	resourceQuota, containerLimit, err := h.rancherClient.GetNamespaceDefaults(ctx, clusterID, projectID)
+	if err != nil {
+		logger.Warn("Failed to get project namespace defaults, assigning namespace without quota annotations",
+			slog.String("namespace", namespace.Name),
+			slog.String("cluster", clusterName),
+			slog.String("cluster_id", clusterID),
+			slog.String("project_id", projectID),
+			slog.String("error", err.Error()),
+		)
+		return nil, nil, fmt.Errorf("%v; namespace %s was assigned without the project's default resource quota and limits", err, namespace.Name)
+	}
+
+	inherited := h.previousProjectDefaults(ctx, namespace, oldNamespace, clusterID+":"+projectID, logger)
+
+	var annotations []annotation
+	var removed []string
+	for _, a := range []annotation{
+		{key: resourceQuotaAnnotation, value: resourceQuota},
+		{key: containerLimitAnnotation, value: containerLimit},
+	} {
+		current, ok := namespace.Annotations[a.key]
+		if ok && (inherited[a.key] == "" || current != inherited[a.key]) {
+			continue
+		}
+		switch {
+		case a.value == "" && ok:
+			removed = append(removed, a.key)
+		case a.value != "" && current != a.value:
+			annotations = append(annotations, a)
+		}
+	}
+	return annotations, removed, nil
+}
+
+// previousProjectDefaults returns the resource quota and container limit
+// annotations of the namespace defaults of the project an UPDATE moves the
+// namespace out of, by key. It returns nil when the namespace isn't changing
+// projects or the previous project's defaults can't be read, in which case
+// the namespace's annotations are kept as its own.
+func (h *Handler) previousProjectDefaults(ctx context.Context, namespace, oldNamespace *corev1.Namespace, projectAnnotationValue string, logger *slog.Logger) map[string]string {
+	if oldNamespace == nil {
+		return nil
+	}
+	previous := oldNamespace.Annotations[h.projectAnnotation]
+	if previous == "" || previous == projectAnnotationValue {
+		return nil
+	}
+	clusterID, projectID, ok := strings.Cut(previous, ":")
+	if !ok {
+		return nil
+	}
+
+	resourceQuota, containerLimit, err := h.rancherClient.GetNamespaceDefaults(ctx, clusterID, projectID)
+	if err != nil {
+		logger.Debug("Failed to get previous project namespace defaults, keeping namespace quota annotations",
+			slog.String("namespace", namespace.Name),
+			slog.String("annotation", previous),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	return map[string]string{
+		resourceQuotaAnnotation:  resourceQuota,
+		containerLimitAnnotation: containerLimit,
+	}
+}
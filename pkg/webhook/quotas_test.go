@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMutate_NamespaceDefaultAnnotations(t *testing.T) {
+	const (
+		resourceQuota  = `{"limit":{"limitsCpu":"500m"}}`
+		containerLimit = `{"limitsMemory":"256Mi"}`
+	)
+
+	tests := []struct {
+		name          string
+		client        *mockRancherClient
+		annotations   map[string]string
+		expected      map[string]string
+		expectWarning bool
+	}{
+		{
+			name:   "project with quotas",
+			client: &mockRancherClient{resourceQuota: resourceQuota, containerLimit: containerLimit},
+			expected: map[string]string{
+				"field.cattle.io/projectId":                     "c-m-abc123:p-xyz789",
+				"field.cattle.io/resourceQuota":                 resourceQuota,
+				"field.cattle.io/containerDefaultResourceLimit": containerLimit,
+			},
+		},
+		{
+			name:   "project without quotas",
+			client: &mockRancherClient{},
+			expected: map[string]string{
+				"field.cattle.io/projectId": "c-m-abc123:p-xyz789",
+			},
+		},
+		{
+			name:        "namespace sets its own quota",
+			client:      &mockRancherClient{resourceQuota: resourceQuota, containerLimit: containerLimit},
+			annotations: map[string]string{"field.cattle.io/resourceQuota": `{"limit":{"limitsCpu":"100m"}}`},
+			expected: map[string]string{
+				"field.cattle.io/projectId":                     "c-m-abc123:p-xyz789",
+				"field.cattle.io/containerDefaultResourceLimit": containerLimit,
+			},
+		},
+		{
+			name:        "already assigned namespace missing quotas",
+			client:      &mockRancherClient{resourceQuota: resourceQuota, containerLimit: containerLimit},
+			annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+			expected: map[string]string{
+				"field.cattle.io/resourceQuota":                 resourceQuota,
+				"field.cattle.io/containerDefaultResourceLimit": containerLimit,
+			},
+		},
+		{
+			name:   "lookup fails",
+			client: &mockRancherClient{defaultsErr: errors.New("connection refused")},
+			expected: map[string]string{
+				"field.cattle.io/projectId": "c-m-abc123:p-xyz789",
+			},
+			expectWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			tt.client.clusterID, tt.client.projectID = "c-m-abc123", "p-xyz789"
+			handler := NewHandler(tt.client, logger, testHandlerConfig())
+
+			review := createAdmissionReview(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-ns",
+					Labels:      map[string]string{"project": "platform"},
+					Annotations: tt.annotations,
+				},
+			}, admissionv1.Create)
+			response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			var patch []map[string]any
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("failed to unmarshal patch: %v", err)
+			}
+			added := make(map[string]string)
+			for _, op := range patch {
+				key, ok := strings.CutPrefix(op["path"].(string), "/metadata/annotations/")
+				if !ok {
+					continue
+				}
+				added[strings.ReplaceAll(key, "~1", "/")] = op["value"].(string)
+			}
+			if len(added) != len(tt.expected) {
+				t.Errorf("expected annotations %v, got %v", tt.expected, added)
+			}
+			for key, value := range tt.expected {
+				if added[key] != value {
+					t.Errorf("expected %s=%s, got %q", key, value, added[key])
+				}
+			}
+
+			if hasWarning := len(response.Warnings) > 0; hasWarning != tt.expectWarning {
+				t.Errorf("expected warning=%v, got %q", tt.expectWarning, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestMutate_NamespaceDefaultAnnotationsOnProjectMove(t *testing.T) {
+	const (
+		oldQuota  = `{"limit":{"limitsCpu":"500m"}}`
+		oldLimit  = `{"limitsMemory":"256Mi"}`
+		newQuota  = `{"limit":{"limitsCpu":"2"}}`
+		ownQuota  = `{"limit":{"limitsCpu":"100m"}}`
+		quotaPath = "/metadata/annotations/field.cattle.io~1resourceQuota"
+		limitPath = "/metadata/annotations/field.cattle.io~1containerDefaultResourceLimit"
+	)
+
+	tests := []struct {
+		name        string
+		newDefaults mockDefaults
+		annotations map[string]string
+		expected    map[string]string
+	}{
+		{
+			name:        "defaults from the previous project are replaced or removed",
+			newDefaults: mockDefaults{resourceQuota: newQuota},
+			annotations: map[string]string{resourceQuotaAnnotation: oldQuota, containerLimitAnnotation: oldLimit},
+			expected: map[string]string{
+				"add " + quotaPath:    newQuota,
+				"remove " + limitPath: "",
+			},
+		},
+		{
+			name:        "namespace's own quota is kept",
+			newDefaults: mockDefaults{resourceQuota: newQuota},
+			annotations: map[string]string{resourceQuotaAnnotation: ownQuota},
+			expected:    map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client := &mockRancherClient{
+				clusterID: "c-m-abc123",
+				projectID: "p-xyz789",
+				defaultsByProject: map[string]mockDefaults{
+					"p-old01":  {resourceQuota: oldQuota, containerLimit: oldLimit},
+					"p-xyz789": tt.newDefaults,
+				},
+			}
+			handler := NewHandler(client, logger, testHandlerConfig())
+
+			oldAnnotations := map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-old01"}
+			for key, value := range tt.annotations {
+				oldAnnotations[key] = value
+			}
+			oldNs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-ns",
+				Labels:      map[string]string{"project": "legacy"},
+				Annotations: oldAnnotations,
+			}}
+			ns := oldNs.DeepCopy()
+			ns.Labels["project"] = "platform"
+
+			review := createAdmissionReviewWithOld(ns, oldNs, admissionv1.Update)
+			response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			var patch []map[string]any
+			if err := json.Unmarshal(response.Patch, &patch); err != nil {
+				t.Fatalf("failed to unmarshal patch: %v", err)
+			}
+			changed := make(map[string]string)
+			for _, op := range patch {
+				path := op["path"].(string)
+				if path == quotaPath || path == limitPath {
+					value, _ := op["value"].(string)
+					changed[op["op"].(string)+" "+path] = value
+				}
+			}
+			if len(changed) != len(tt.expected) {
+				t.Errorf("expected quota changes %v, got %v", tt.expected, changed)
+			}
+			for key, value := range tt.expected {
+				if got, ok := changed[key]; !ok || got != value {
+					t.Errorf("expected %s=%q, got %q", key, value, got)
+				}
+			}
+		})
+	}
+}
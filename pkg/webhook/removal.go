@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LabelRemovalPolicy decides what happens to a namespace's project assignment
+// when its project label is removed
+type LabelRemovalPolicy string
+
+const (
+	// LabelRemovalKeep leaves the namespace in its current project
+	LabelRemovalKeep LabelRemovalPolicy = "keep"
+	// LabelRemovalRemove removes the project annotation, unassigning the namespace
+	LabelRemovalRemove LabelRemovalPolicy = "remove"
+	// LabelRemovalDefault moves the namespace to the default project
+	LabelRemovalDefault LabelRemovalPolicy = "default"
+)
+
+// ParseLabelRemovalPolicy validates a label removal policy name
+func ParseLabelRemovalPolicy(s string) (LabelRemovalPolicy, error) {
+	switch policy := LabelRemovalPolicy(strings.TrimSpace(s)); policy {
+	case LabelRemovalKeep, LabelRemovalRemove, LabelRemovalDefault:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid label removal policy %q (must be keep, remove or default)", s)
+	}
+}
+
+// projectLabelRemoved reports whether an UPDATE removes the project label (or
+// whichever project source the previous version of the namespace had) and
+// leaves the namespace with no project source
+func (h *Handler) projectLabelRemoved(req *admissionv1.AdmissionRequest, namespace *corev1.Namespace) bool {
+	if req.Operation != admissionv1.Update || req.OldObject.Raw == nil {
+		return false
+	}
+	if project, _ := h.projectFromSources(namespace); project != "" {
+		return false
+	}
+
+	var oldNamespace corev1.Namespace
+	if err := json.Unmarshal(req.OldObject.Raw, &oldNamespace); err != nil {
+		return false
+	}
+	oldProject, _ := h.projectFromSources(&oldNamespace)
+	return oldProject != ""
+}
+
+// removeProjectAnnotation unassigns a namespace from its project by removing
+// the project annotation
+func (h *Handler) removeProjectAnnotation(req *admissionv1.AdmissionRequest, namespace *corev1.Namespace, clusterName string, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	current, ok := namespace.Annotations[h.projectAnnotation]
+	if !ok {
+		logger.Debug("Project label removed and namespace has no project annotation, skipping",
+			slog.String("namespace", namespace.Name),
+			slog.String("cluster", clusterName),
+		)
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
+	}
+
+	if h.dryRun {
+		logger.Info("[DRY-RUN] Would remove project annotation from namespace",
+			slog.String("namespace", namespace.Name),
+			slog.String("cluster", clusterName),
+			slog.String("annotation", current),
+			slog.String("operation", string(req.Operation)),
+		)
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusDryRun
+	}
+
+	patch := []map[string]any{
+		{
+			"op":   "remove",
+			"path": fmt.Sprintf("/metadata/annotations/%s", escapeJSONPointer(h.projectAnnotation)),
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		logger.Error("Failed to marshal patch", slog.String("error", err.Error()))
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: fmt.Sprintf("failed to marshal patch: %v", err),
+			},
+		}, metrics.StatusError
+	}
+
+	logger.Info("Project label removed, removing project annotation from namespace",
+		slog.String("namespace", namespace.Name),
+		slog.String("cluster", clusterName),
+		slog.String("annotation", current),
+		slog.String("operation", string(req.Operation)),
+	)
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+		AuditAnnotations: map[string]string{
+			auditDecision:   decisionUnassigned,
+			auditAnnotation: current,
+		},
+	}, metrics.StatusMutated
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseLabelRemovalPolicy(t *testing.T) {
+	for _, valid := range []string{"keep", "remove", "default"} {
+		if _, err := ParseLabelRemovalPolicy(valid); err != nil {
+			t.Errorf("unexpected error for %q: %v", valid, err)
+		}
+	}
+	if _, err := ParseLabelRemovalPolicy("delete"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestMutate_LabelRemoved(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         LabelRemovalPolicy
+		defaultProject string
+		annotated      bool
+		dryRun         bool
+		expectedStatus string
+		expectedPatch  string
+	}{
+		{
+			name:           "keep by default",
+			annotated:      true,
+			expectedStatus: metrics.StatusSkipped,
+		},
+		{
+			name:           "remove annotation",
+			policy:         LabelRemovalRemove,
+			annotated:      true,
+			expectedStatus: metrics.StatusMutated,
+			expectedPatch:  `[{"op":"remove","path":"/metadata/annotations/field.cattle.io~1projectId"}]`,
+		},
+		{
+			name:           "remove without annotation",
+			policy:         LabelRemovalRemove,
+			expectedStatus: metrics.StatusSkipped,
+		},
+		{
+			name:           "remove in dry-run mode",
+			policy:         LabelRemovalRemove,
+			annotated:      true,
+			dryRun:         true,
+			expectedStatus: metrics.StatusDryRun,
+		},
+		{
+			name:           "move to default project",
+			policy:         LabelRemovalDefault,
+			defaultProject: "sandbox",
+			annotated:      true,
+			expectedStatus: metrics.StatusMutated,
+			expectedPatch:  `[{"op":"add","path":"/metadata/annotations/field.cattle.io~1projectId","value":"c-m-abc123:p-sandbx"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			cfg.LabelRemovalPolicy = tt.policy
+			cfg.DefaultProject = tt.defaultProject
+			cfg.DryRun = tt.dryRun
+			handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-sandbx"}, logger, cfg)
+
+			// The update removes the project label, and keeps the annotation if any
+			annotation := ""
+			if tt.annotated {
+				annotation = "c-m-abc123:p-old12"
+			}
+			review := createAdmissionReviewWithOld(newTestNamespace("", annotation), newTestNamespace("platform", annotation), admissionv1.Update)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if !response.Allowed {
+				t.Error("expected request to be allowed")
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if string(response.Patch) != tt.expectedPatch {
+				t.Errorf("expected patch %s, got %s", tt.expectedPatch, string(response.Patch))
+			}
+		})
+	}
+}
+
+func TestProjectLabelRemoved(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewHandler(nil, logger, testHandlerConfig())
+
+	labeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: map[string]string{"project": "platform"}}}
+	unlabeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}}
+
+	tests := []struct {
+		name     string
+		review   *admissionv1.AdmissionReview
+		expected bool
+	}{
+		{"label removed", createAdmissionReviewWithOld(unlabeled, labeled, admissionv1.Update), true},
+		{"label kept", createAdmissionReviewWithOld(labeled, labeled, admissionv1.Update), false},
+		{"never labeled", createAdmissionReviewWithOld(unlabeled, unlabeled, admissionv1.Update), false},
+		{"create", createAdmissionReview(unlabeled, admissionv1.Create), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var namespace corev1.Namespace
+			if err := json.Unmarshal(tt.review.Request.Object.Raw, &namespace); err != nil {
+				t.Fatalf("failed to unmarshal namespace: %v", err)
+			}
+			if got := handler.projectLabelRemoved(tt.review.Request, &namespace); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NamespaceReview is what the webhook decides for a namespace that already exists
+type NamespaceReview struct {
+	// Patch is the JSON patch assigning the namespace to its project, nil when there is nothing to change
+	Patch []byte
+	// Annotation is the project annotation Patch sets
+	Annotation string
+	// Denied is set when the webhook would reject the namespace, with the reason in Message
+	Denied  bool
+	Message string
+	// Warnings are the warnings the webhook would return
+	Warnings []string
+	// CreatesProject is the project a report-only review would have created
+	// to assign the namespace to, empty when no project is missing
+	CreatesProject string
+	// Status is how the review ended, as recorded in request metrics (mutated, skipped, denied, ...)
+	Status string
+}
+
+// ReviewNamespace runs a namespace that already exists in a cluster through
+// the same logic as an admission request creating it, made by user. It lets
+// namespaces that never went through the webhook be assigned after the fact.
+// clusterID may be empty to look it up from clusterName. In dry-run mode no
+// patch is returned.
+//
+// Reviews don't record admission metrics. In report mode the review has no
+// side effects at all: a missing project that would be created is returned
+// in CreatesProject instead.
+func (h *Handler) ReviewNamespace(ctx context.Context, clusterName, clusterID string, namespace *corev1.Namespace, user authenticationv1.UserInfo, report bool) (NamespaceReview, error) {
+	raw, err := json.Marshal(namespace)
+	if err != nil {
+		return NamespaceReview{}, fmt.Errorf("failed to marshal namespace %s: %w", namespace.Name, err)
+	}
+
+	req := &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+		Name:      namespace.Name,
+		Operation: admissionv1.Create,
+		UserInfo:  user,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+
+	reviewer := *h
+	reviewer.reviewing, reviewer.reportOnly = true, report
+	response, status := reviewer.mutate(ctx, req, clusterRef{name: clusterName, id: clusterID}, h.logger)
+	review := NamespaceReview{
+		Patch:    response.Patch,
+		Denied:   !response.Allowed,
+		Warnings: response.Warnings,
+		Status:   status,
+	}
+	if response.Result != nil {
+		review.Message = response.Result.Message
+	}
+	if response.AuditAnnotations[auditDecision] == decisionWouldCreate {
+		review.CreatesProject = response.AuditAnnotations[auditProject]
+	}
+	if status == metrics.StatusMutated {
+		review.Annotation = response.AuditAnnotations[auditAnnotation]
+	}
+	return review, nil
+}
@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"github.com/rvbsalgado/fencemaster/pkg/rancher"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReviewNamespace(t *testing.T) {
+	tests := []struct {
+		name           string
+		client         *mockRancherClient
+		cfg            func(*HandlerConfig)
+		namespace      *corev1.Namespace
+		wantStatus     string
+		wantAnnotation string
+		wantDenied     bool
+	}{
+		{
+			name:   "unassigned namespace",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{"project": "platform"},
+			}},
+			wantStatus:     metrics.StatusMutated,
+			wantAnnotation: "c-m-abc123:p-xyz789",
+		},
+		{
+			name:   "already assigned",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "payments",
+				Labels:      map[string]string{"project": "platform"},
+				Annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-xyz789"},
+			}},
+			wantStatus: metrics.StatusSkipped,
+		},
+		{
+			name:   "annotation drifted from the label",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "payments",
+				Labels:      map[string]string{"project": "platform"},
+				Annotations: map[string]string{"field.cattle.io/projectId": "c-m-abc123:p-old00"},
+			}},
+			wantStatus:     metrics.StatusMutated,
+			wantAnnotation: "c-m-abc123:p-xyz789",
+		},
+		{
+			name:   "project not found in strict mode",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectErr: errors.New("project not found")},
+			cfg:    func(cfg *HandlerConfig) { cfg.StrictMode = true },
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{"project": "platform"},
+			}},
+			wantStatus: metrics.StatusDenied,
+			wantDenied: true,
+		},
+		{
+			name:   "dry-run",
+			client: &mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"},
+			cfg:    func(cfg *HandlerConfig) { cfg.DryRun = true },
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{"project": "platform"},
+			}},
+			wantStatus: metrics.StatusDryRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := testHandlerConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			handler := NewHandler(tt.client, logger, cfg)
+
+			review, err := handler.ReviewNamespace(context.Background(), "test-cluster", "", tt.namespace,
+				authenticationv1.UserInfo{Username: "system:serviceaccount:fencemaster:fencemaster"}, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if review.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, review.Status)
+			}
+			if review.Annotation != tt.wantAnnotation {
+				t.Errorf("expected annotation %q, got %q", tt.wantAnnotation, review.Annotation)
+			}
+			if review.Denied != tt.wantDenied {
+				t.Errorf("expected denied=%v, got %v (%s)", tt.wantDenied, review.Denied, review.Message)
+			}
+			if hasPatch := review.Patch != nil; hasPatch != (tt.wantAnnotation != "") {
+				t.Errorf("expected patch only with an annotation, got %s", review.Patch)
+			}
+			if review.Patch != nil && !json.Valid(review.Patch) {
+				t.Errorf("invalid patch %s", review.Patch)
+			}
+		})
+	}
+}
+
+func TestReviewNamespace_ClusterID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// The cluster lookup would fail; a known cluster ID skips it
+	client := &mockRancherClient{clusterErr: errors.New("cluster not found"), projectID: "p-xyz789"}
+	handler := NewHandler(client, logger, testHandlerConfig())
+
+	review, err := handler.ReviewNamespace(context.Background(), "test-cluster", "c-m-abc123", &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{"project": "platform"}},
+	}, authenticationv1.UserInfo{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if review.Annotation != "c-m-abc123:p-xyz789" {
+		t.Errorf("expected annotation from the given cluster ID, got %q (%s)", review.Annotation, review.Status)
+	}
+}
+
+func TestReviewNamespace_Report(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.CreateProjects = true
+	client := &mockRancherClient{clusterID: "c-m-abc123", projectErr: rancher.NewNotFoundError(errors.New("project payments not found"))}
+	handler := NewHandler(client, logger, cfg)
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments-api", Labels: map[string]string{"project": "payments"}},
+	}
+
+	review, err := handler.ReviewNamespace(context.Background(), "test-cluster", "c-m-abc123", namespace, authenticationv1.UserInfo{}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.created) != 0 {
+		t.Fatalf("expected no project to be created in report mode, got %v", client.created)
+	}
+	if review.CreatesProject != "payments" || review.Patch != nil {
+		t.Errorf("expected the missing project to be reported without a patch, got %q (%s)", review.CreatesProject, review.Patch)
+	}
+
+	review, err = handler.ReviewNamespace(context.Background(), "test-cluster", "c-m-abc123", namespace, authenticationv1.UserInfo{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(client.created, []string{"payments"}) {
+		t.Errorf("expected the project to be created outside report mode, got %v", client.created)
+	}
+	if review.CreatesProject != "" || review.Annotation != "c-m-abc123:p-new01" {
+		t.Errorf("expected the namespace to be assigned to the created project, got %q (created %q)", review.Annotation, review.CreatesProject)
+	}
+}
+
+func TestReviewNamespace_NoAdmissionMetrics(t *testing.T) {
+	metrics.ProjectSourcesTotal.Reset()
+	metrics.ProjectConflictsTotal.Reset()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-xyz789"}, logger, testHandlerConfig())
+
+	for _, report := range []bool{true, false} {
+		review, err := handler.ReviewNamespace(context.Background(), "test-cluster", "c-m-abc123", newTestNamespace("platform", "c-m-abc123:p-manual"), authenticationv1.UserInfo{}, report)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if review.Annotation != "c-m-abc123:p-xyz789" {
+			t.Errorf("expected the conflicting annotation to be replaced, got %q (%s)", review.Annotation, review.Status)
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.ProjectSourcesTotal.WithLabelValues("label:project")); got != 0 {
+		t.Errorf("expected reviews to leave project sources alone, got %f", got)
+	}
+	if got := testutil.ToFloat64(metrics.ProjectConflictsTotal.WithLabelValues(string(ConflictLabelWins))); got != 0 {
+		t.Errorf("expected reviews to leave conflicts alone, got %f", got)
+	}
+}
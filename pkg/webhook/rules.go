@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	"sigs.k8s.io/yaml"
+)
+
+// ProjectRule derives a project from a namespace name. Project may refer to
+// capture groups of Match as $1 or ${name}.
+type ProjectRule struct {
+	Match   *regexp.Regexp
+	Project string
+}
+
+// projectRulesFile is the format of the project rules file
+type projectRulesFile struct {
+	Rules []struct {
+		Match   string `json:"match"`
+		Project string `json:"project"`
+	} `json:"rules"`
+}
+
+// LoadProjectRules reads namespace name rules from a YAML file of the form
+//
+//	rules:
+//	  - match: ^payments-.*
+//	    project: payments
+//	  - match: ^team-([a-z]+)-
+//	    project: $1
+func LoadProjectRules(rulesFile string) ([]ProjectRule, error) {
+	data, err := os.ReadFile(rulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project rules file: %w", err)
+	}
+
+	rules, err := parseProjectRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project rules file %s: %w", rulesFile, err)
+	}
+	return rules, nil
+}
+
+// parseProjectRules compiles the rules in a project rules file, keeping their order
+func parseProjectRules(data []byte) ([]ProjectRule, error) {
+	var file projectRulesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+
+	rules := make([]ProjectRule, 0, len(file.Rules))
+	for i, rule := range file.Rules {
+		if rule.Match == "" || rule.Project == "" {
+			return nil, fmt.Errorf("rule %d: match and project must not be empty", i+1)
+		}
+		match, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules = append(rules, ProjectRule{Match: match, Project: rule.Project})
+	}
+	return rules, nil
+}
+
+// projectFromName derives the project of a namespace without a project label:
+// the first project rule matching its name, else the cluster's default project.
+// It also returns which of the two the project came from, and "" when there is neither.
+func (h *Handler) projectFromName(namespaceName string, cluster clusterRef, logger *slog.Logger) (string, string) {
+	for _, rule := range h.projectRules {
+		match := rule.Match.FindStringSubmatchIndex(namespaceName)
+		if match == nil {
+			continue
+		}
+
+		project := string(rule.Match.ExpandString(nil, rule.Project, namespaceName, match))
+		if project == "" {
+			continue
+		}
+		logger.Debug("Namespace has no project label, assigning project from name rule",
+			slog.String("namespace", namespaceName),
+			slog.String("cluster", cluster.name),
+			slog.String("rule", rule.Match.String()),
+			slog.String("project", project),
+		)
+		return project, metrics.ProjectSourceRule
+	}
+
+	project := h.defaultProjectFor(cluster)
+	if project == "" {
+		return "", ""
+	}
+	logger.Debug("Namespace has no project label, assigning the default project",
+		slog.String("namespace", namespaceName),
+		slog.String("cluster", cluster.name),
+		slog.String("project", project),
+	)
+	return project, metrics.ProjectSourceDefault
+}
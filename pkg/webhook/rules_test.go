@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadProjectRules(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `rules:
+  - match: ^payments-.*
+    project: payments
+  - match: ^team-(?P<team>[a-z]+)-
+    project: team-${team}
+`
+	if err := os.WriteFile(rulesFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadProjectRules(rulesFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	if rules[0].Match.String() != "^payments-.*" || rules[1].Project != "team-${team}" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+}
+
+func TestParseProjectRules_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid regex":   "rules:\n  - match: '^payments-('\n    project: payments\n",
+		"missing project": "rules:\n  - match: ^payments-\n",
+		"unknown field":   "rules:\n  - match: ^payments-\n    project: payments\n    cluster: prod\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProjectRules([]byte(content)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestProjectFromName(t *testing.T) {
+	rules, err := parseProjectRules([]byte(`rules:
+  - match: ^payments-.*
+    project: payments
+  - match: ^team-([a-z]+)-
+    project: $1
+  - match: ^svc-(?P<project>[a-z]+)$
+    project: ${project}-services
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ProjectRules = rules
+	cfg.DefaultProject = "Default"
+	handler := NewHandler(nil, logger, cfg)
+
+	tests := map[string]string{
+		"payments-api":   "payments",
+		"team-orange-ci": "orange",
+		"svc-billing":    "billing-services",
+		"legacy-app":     "Default",
+	}
+	for name, expected := range tests {
+		if got, _ := handler.projectFromName(name, clusterRef{name: "test-cluster"}, logger); got != expected {
+			t.Errorf("projectFromName(%s) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestMutate_ProjectRule(t *testing.T) {
+	rules, err := parseProjectRules([]byte("rules:\n  - match: ^payments-\n    project: payments\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ProjectRules = rules
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-paymnt"}, logger, cfg)
+
+	tests := []struct {
+		name           string
+		namespace      string
+		expectedStatus string
+	}{
+		{name: "matching name", namespace: "payments-api", expectedStatus: metrics.StatusMutated},
+		{name: "no matching rule", namespace: "legacy-app", expectedStatus: metrics.StatusSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tt.namespace}}
+			review := createAdmissionReview(ns, admissionv1.Create)
+			response, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if !response.Allowed {
+				t.Error("expected request to be allowed")
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+		})
+	}
+}
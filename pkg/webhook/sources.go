@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ProjectSourceKind is where on a namespace a project source reads from
+type ProjectSourceKind string
+
+const (
+	// ProjectSourceLabel reads the project from a label
+	ProjectSourceLabel ProjectSourceKind = "label"
+	// ProjectSourceAnnotation reads the project from an annotation
+	ProjectSourceAnnotation ProjectSourceKind = "annotation"
+	// ProjectSourceTracking maps the value of a GitOps tracking label (e.g.
+	// argocd.argoproj.io/instance) to a project through a lookup table
+	ProjectSourceTracking ProjectSourceKind = "tracking"
+)
+
+// ProjectSource is one place a namespace's project can be read from
+type ProjectSource struct {
+	Kind ProjectSourceKind
+	Key  string
+}
+
+// String returns the source as configured and shown in logs and metrics, e.g. "label:project"
+func (s ProjectSource) String() string {
+	return string(s.Kind) + ":" + s.Key
+}
+
+// ParseProjectSources parses an ordered list of sources of the form
+// "label:project,annotation:example.com/project,tracking:argocd.argoproj.io/instance"
+func ParseProjectSources(s string) ([]ProjectSource, error) {
+	var sources []ProjectSource
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kind, key, found := strings.Cut(item, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid project source %q (expected kind:key)", item)
+		}
+
+		source := ProjectSource{Kind: ProjectSourceKind(strings.TrimSpace(kind)), Key: key}
+		switch source.Kind {
+		case ProjectSourceLabel, ProjectSourceAnnotation, ProjectSourceTracking:
+		default:
+			return nil, fmt.Errorf("invalid project source %q (kind must be label, annotation or tracking)", item)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// LoadTrackingProjects reads the lookup tables of tracking sources from a YAML
+// file mapping each tracking label to a table of label values and projects:
+//
+//	argocd.argoproj.io/instance:
+//	  payments-api: payments
+//	kustomize.toolkit.fluxcd.io/name:
+//	  payments: payments
+func LoadTrackingProjects(trackingFile string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(trackingFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking projects file: %w", err)
+	}
+
+	var tracking map[string]map[string]string
+	if err := yaml.UnmarshalStrict(data, &tracking); err != nil {
+		return nil, fmt.Errorf("failed to load tracking projects file %s: %w", trackingFile, err)
+	}
+	return tracking, nil
+}
+
+// projectFromSources returns the project from the first source the namespace
+// has a value for, and that source. Empty values are ignored, as are tracking
+// label values without an entry in the lookup table.
+func (h *Handler) projectFromSources(namespace *corev1.Namespace) (string, string) {
+	for _, source := range h.projectSources {
+		var project string
+		switch source.Kind {
+		case ProjectSourceLabel:
+			project = namespace.Labels[source.Key]
+		case ProjectSourceAnnotation:
+			project = namespace.Annotations[source.Key]
+		case ProjectSourceTracking:
+			project = h.trackingProjects[source.Key][namespace.Labels[source.Key]]
+		}
+		if project != "" {
+			return project, source.String()
+		}
+	}
+	return "", ""
+}
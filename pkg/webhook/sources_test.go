@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseProjectSources(t *testing.T) {
+	sources, err := ParseProjectSources("label:project, label:team.example.com/project,annotation:example.com/project,tracking:argocd.argoproj.io/instance")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"label:project", "label:team.example.com/project", "annotation:example.com/project", "tracking:argocd.argoproj.io/instance"}
+	if len(sources) != len(expected) {
+		t.Fatalf("expected %d sources, got %d", len(expected), len(sources))
+	}
+	for i, source := range sources {
+		if source.String() != expected[i] {
+			t.Errorf("source %d: expected %s, got %s", i, expected[i], source)
+		}
+	}
+
+	for _, invalid := range []string{"project", "label:", "owner:team"} {
+		if _, err := ParseProjectSources(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadTrackingProjects(t *testing.T) {
+	trackingFile := filepath.Join(t.TempDir(), "tracking.yaml")
+	content := `argocd.argoproj.io/instance:
+  payments-api: payments
+kustomize.toolkit.fluxcd.io/name:
+  billing: finance
+`
+	if err := os.WriteFile(trackingFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tracking, err := LoadTrackingProjects(trackingFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tracking["argocd.argoproj.io/instance"]["payments-api"] != "payments" || tracking["kustomize.toolkit.fluxcd.io/name"]["billing"] != "finance" {
+		t.Errorf("unexpected tracking projects: %v", tracking)
+	}
+}
+
+func TestProjectFromSources(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ProjectSources = []ProjectSource{
+		{Kind: ProjectSourceLabel, Key: "project"},
+		{Kind: ProjectSourceLabel, Key: "team.example.com/project"},
+		{Kind: ProjectSourceAnnotation, Key: "example.com/project"},
+		{Kind: ProjectSourceTracking, Key: "argocd.argoproj.io/instance"},
+	}
+	cfg.TrackingProjects = map[string]map[string]string{
+		"argocd.argoproj.io/instance": {"payments-api": "payments"},
+	}
+	handler := NewHandler(nil, logger, cfg)
+
+	tests := []struct {
+		name            string
+		labels          map[string]string
+		annotations     map[string]string
+		expectedProject string
+		expectedSource  string
+	}{
+		{
+			name:            "first source wins",
+			labels:          map[string]string{"project": "platform", "team.example.com/project": "team"},
+			expectedProject: "platform",
+			expectedSource:  "label:project",
+		},
+		{
+			name:            "empty label is skipped",
+			labels:          map[string]string{"project": "", "team.example.com/project": "team"},
+			expectedProject: "team",
+			expectedSource:  "label:team.example.com/project",
+		},
+		{
+			name:            "annotation",
+			annotations:     map[string]string{"example.com/project": "data"},
+			expectedProject: "data",
+			expectedSource:  "annotation:example.com/project",
+		},
+		{
+			name:            "tracking label",
+			labels:          map[string]string{"argocd.argoproj.io/instance": "payments-api"},
+			expectedProject: "payments",
+			expectedSource:  "tracking:argocd.argoproj.io/instance",
+		},
+		{
+			name:   "tracking label without lookup entry",
+			labels: map[string]string{"argocd.argoproj.io/instance": "unknown-app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Labels: tt.labels, Annotations: tt.annotations},
+			}
+			project, source := handler.projectFromSources(ns)
+			if project != tt.expectedProject || source != tt.expectedSource {
+				t.Errorf("expected %q from %q, got %q from %q", tt.expectedProject, tt.expectedSource, project, source)
+			}
+		})
+	}
+}
+
+func TestMutate_ProjectSourceMetric(t *testing.T) {
+	metrics.ProjectSourcesTotal.Reset()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := testHandlerConfig()
+	cfg.ProjectSources = []ProjectSource{
+		{Kind: ProjectSourceLabel, Key: "project"},
+		{Kind: ProjectSourceAnnotation, Key: "example.com/project"},
+	}
+	handler := NewHandler(&mockRancherClient{clusterID: "c-m-abc123", projectID: "p-abc12"}, logger, cfg)
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ns",
+			Annotations: map[string]string{"example.com/project": "platform"},
+		},
+	}
+	review := createAdmissionReview(ns, admissionv1.Create)
+	_, status := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+	if status != metrics.StatusMutated {
+		t.Errorf("expected status '%s', got '%s'", metrics.StatusMutated, status)
+	}
+	if got := testutil.ToFloat64(metrics.ProjectSourcesTotal.WithLabelValues("annotation:example.com/project")); got != 1 {
+		t.Errorf("expected annotation source count of 1, got %f", got)
+	}
+}
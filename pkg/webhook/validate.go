@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HandleValidate handles admission requests at /validate/{cluster-name} and
+// /validate/id/{cluster-id}. It runs after mutation and rejects namespaces
+// whose project annotation doesn't hold up, so the annotation can't be patched
+// later to move a namespace into another project.
+func (h *Handler) HandleValidate(w http.ResponseWriter, r *http.Request) {
+	h.serveAdmission(w, r, "/validate/", h.validate)
+}
+
+func (h *Handler) validate(ctx context.Context, req *admissionv1.AdmissionRequest, cluster clusterRef, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	clusterName := cluster.name
+
+	if req.Kind.Kind != "Namespace" || req.Operation == admissionv1.Delete {
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
+	}
+
+	var namespace corev1.Namespace
+	if err := json.Unmarshal(req.Object.Raw, &namespace); err != nil {
+		logger.Error("Failed to unmarshal namespace", slog.String("error", err.Error()))
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: fmt.Sprintf("failed to unmarshal namespace: %v", err),
+			},
+		}, metrics.StatusError
+	}
+
+	if h.isNamespaceExcluded(namespace.Name) {
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusSkipped
+	}
+
+	annotation := namespace.Annotations[h.projectAnnotation]
+	projectName, source := h.projectFromSources(&namespace)
+
+	var oldNamespace *corev1.Namespace
+	if req.Operation == admissionv1.Update && req.OldObject.Raw != nil {
+		var old corev1.Namespace
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err == nil {
+			oldNamespace = &old
+		}
+	}
+	// Whether the annotation was set by this request rather than kept from before
+	annotationChanged := oldNamespace == nil || annotation != oldNamespace.Annotations[h.projectAnnotation]
+
+	// Labeled namespaces follow their label: the annotation may only change
+	// with it, unless the conflict policy keeps annotations that disagree
+	if oldNamespace != nil && projectName != "" && h.conflictPolicy != ConflictAnnotationWins {
+		oldProjectName, _ := h.projectFromSources(oldNamespace)
+		oldAnnotation := oldNamespace.Annotations[h.projectAnnotation]
+		if oldProjectName == projectName && oldAnnotation != "" && annotation != oldAnnotation {
+			return h.deny(&namespace, clusterName, metrics.ValidationReasonAnnotationEdited, logger,
+				"the %s annotation of namespace %s is managed by its %s label: change the label to move it to another project",
+				h.projectAnnotation, namespace.Name, source)
+		}
+	}
+
+	if annotation == "" {
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusAllowed
+	}
+
+	annotationClusterID, projectID, ok := strings.Cut(annotation, ":")
+	if !ok || annotationClusterID == "" || !projectIDPattern.MatchString(projectID) {
+		return h.deny(&namespace, clusterName, metrics.ValidationReasonInvalid, logger,
+			"invalid %s annotation %q (expected clusterID:projectID)", h.projectAnnotation, annotation)
+	}
+
+	clusterID, err := h.clusterID(ctx, cluster)
+	if err != nil {
+		return h.validationLookupFailed(&namespace, clusterName, fmt.Errorf("failed to get cluster ID: %w", err), logger)
+	}
+
+	if annotationClusterID != clusterID {
+		return h.deny(&namespace, clusterName, metrics.ValidationReasonClusterMismatch, logger,
+			"project %s belongs to cluster %s, not to cluster %s", projectID, annotationClusterID, clusterID)
+	}
+
+	exists, err := h.rancherClient.ProjectExists(ctx, clusterID, projectID)
+	if err != nil {
+		return h.validationLookupFailed(&namespace, clusterName, err, logger)
+	}
+	if !exists {
+		return h.deny(&namespace, clusterName, metrics.ValidationReasonProjectNotFound, logger,
+			"project %s not found in cluster %s", projectID, clusterID)
+	}
+
+	if projectName != "" {
+		labelProjectID, err := h.resolveProjectID(ctx, clusterID, projectName)
+		if err != nil {
+			return h.validationLookupFailed(&namespace, clusterName, fmt.Errorf("failed to get project ID for '%s': %w", projectName, err), logger)
+		}
+		switch {
+		case labelProjectID != projectID:
+			// Mutation keeps the annotation when the conflict policy says so, and
+			// when it refuses the label's project the namespace stays where it was
+			if h.conflictPolicy == ConflictAnnotationWins ||
+				(!annotationChanged && h.refusesProject(ctx, req, oldNamespace, clusterID, projectName, labelProjectID, logger)) {
+				break
+			}
+			return h.deny(&namespace, clusterName, metrics.ValidationReasonLabelMismatch, logger,
+				"the %s annotation assigns namespace %s to project %s, but its %s label assigns it to project '%s' (%s)",
+				h.projectAnnotation, namespace.Name, projectID, source, projectName, labelProjectID)
+		case annotationChanged && h.refusesProject(ctx, req, oldNamespace, clusterID, projectName, projectID, logger):
+			// Mutation leaves such namespaces unassigned, so the requester set the annotation
+			return h.deny(&namespace, clusterName, metrics.ValidationReasonProjectRefused, logger,
+				"namespace %s cannot be assigned to project '%s' (%s): it is protected or %s may not assign namespaces to it",
+				namespace.Name, projectName, projectID, req.UserInfo.Username)
+		}
+	}
+
+	return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusAllowed
+}
+
+// refusesProject reports whether mutation refuses to assign the namespace to
+// the project its project sources name, because the project is protected or
+// the requester may not assign namespaces to it
+func (h *Handler) refusesProject(ctx context.Context, req *admissionv1.AdmissionRequest, oldNamespace *corev1.Namespace, clusterID, projectName, projectID string, logger *slog.Logger) bool {
+	if h.isProjectProtected(ctx, clusterID, projectName, projectID, logger) {
+		return true
+	}
+	if h.projectAccess == nil || assignedTo(oldNamespace, h.projectAnnotation, clusterID+":"+projectID) {
+		return false
+	}
+	// Failed membership lookups count as not a member, as in mutation
+	allowed, _ := h.projectAccessAllowed(ctx, userRef{username: req.UserInfo.Username, groups: req.UserInfo.Groups}, clusterID, projectName, projectID)
+	return !allowed
+}
+
+// deny rejects a namespace that failed validation, or only logs it in dry-run mode
+func (h *Handler) deny(namespace *corev1.Namespace, clusterName, reason string, logger *slog.Logger, format string, args ...any) (*admissionv1.AdmissionResponse, string) {
+	message := fmt.Sprintf(format, args...)
+	if h.dryRun {
+		logger.Info("[DRY-RUN] Would deny namespace",
+			slog.String("namespace", namespace.Name),
+			slog.String("cluster", clusterName),
+			slog.String("reason", reason),
+			slog.String("message", message),
+		)
+		return &admissionv1.AdmissionResponse{Allowed: true}, metrics.StatusDryRun
+	}
+
+	logger.Warn("Denying namespace",
+		slog.String("namespace", namespace.Name),
+		slog.String("cluster", clusterName),
+		slog.String("reason", reason),
+		slog.String("message", message),
+	)
+	metrics.ValidationDenialsTotal.WithLabelValues(reason).Inc()
+
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Message: message,
+		},
+	}, metrics.StatusDenied
+}
+
+// validationLookupFailed handles a failed cluster or project lookup: the
+// namespace is denied in strict mode and allowed otherwise
+func (h *Handler) validationLookupFailed(namespace *corev1.Namespace, clusterName string, err error, logger *slog.Logger) (*admissionv1.AdmissionResponse, string) {
+	logger.Error("Failed to validate project annotation",
+		slog.String("namespace", namespace.Name),
+		slog.String("cluster", clusterName),
+		slog.String("error", err.Error()),
+	)
+	// Error type is recorded by the rancher client, not here
+	if h.strictMode && !h.dryRun {
+		return &admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}, metrics.StatusDenied
+	}
+	logger.Warn("Allowing namespace without validating project annotation (strict mode disabled)",
+		slog.String("namespace", namespace.Name),
+		slog.String("cluster", clusterName),
+		slog.String("reason", reasonLookupFailed),
+	)
+	return &admissionv1.AdmissionResponse{
+		Allowed:  true,
+		Warnings: []string{fmt.Sprintf("%v; the project annotation of namespace %s was not validated", err, namespace.Name)},
+		AuditAnnotations: map[string]string{
+			auditDecision: decisionUnvalidated,
+			auditReason:   reasonLookupFailed,
+		},
+	}, metrics.StatusAllowed
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rvbsalgado/fencemaster/pkg/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		ns             *corev1.Namespace
+		oldNs          *corev1.Namespace
+		expectedStatus string
+		expectedReason string
+	}{
+		{
+			name:           "no annotation",
+			ns:             newTestNamespace("", ""),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "annotation matches label",
+			ns:             newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "unlabeled namespace in an existing project",
+			ns:             newTestNamespace("", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "malformed annotation",
+			ns:             newTestNamespace("", "p-xyz789"),
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonInvalid,
+		},
+		{
+			name:           "project of another cluster",
+			ns:             newTestNamespace("", "c-m-other:p-xyz789"),
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonClusterMismatch,
+		},
+		{
+			name:           "project doesn't exist",
+			ns:             newTestNamespace("", "c-m-abc123:p-gone1"),
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonProjectNotFound,
+		},
+		{
+			name:           "annotation contradicts label",
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonLabelMismatch,
+		},
+		{
+			name:           "annotation edited on labeled namespace",
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			oldNs:          newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonAnnotationEdited,
+		},
+		{
+			name:           "annotation changed with the label",
+			ns:             newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			oldNs:          newTestNamespace("legacy", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ValidationDenialsTotal.Reset()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client := &mockRancherClient{
+				clusterID:        "c-m-abc123",
+				projectID:        "p-xyz789",
+				existingProjects: []string{"p-xyz789", "p-other"},
+			}
+			handler := NewHandler(client, logger, testHandlerConfig())
+
+			review := createAdmissionReview(tt.ns, admissionv1.Create)
+			if tt.oldNs != nil {
+				review = createAdmissionReviewWithOld(tt.ns, tt.oldNs, admissionv1.Update)
+			}
+			response, status := handler.validate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if response.Allowed != (tt.expectedStatus != metrics.StatusDenied) {
+				t.Errorf("unexpected allowed=%v", response.Allowed)
+			}
+			if tt.expectedReason != "" {
+				if got := testutil.ToFloat64(metrics.ValidationDenialsTotal.WithLabelValues(tt.expectedReason)); got != 1 {
+					t.Errorf("expected 1 denial with reason %s, got %f", tt.expectedReason, got)
+				}
+			}
+		})
+	}
+}
+
+// TestValidate_AgreesWithMutation checks that namespaces admitted by mutation
+// without assigning the label's project aren't rejected by validation, and
+// that annotations mutation undoes are rejected should they get past it
+func TestValidate_AgreesWithMutation(t *testing.T) {
+	strip := &ProjectAccessPolicy{Action: ProjectAccessStrip}
+
+	tests := []struct {
+		name           string
+		configure      func(cfg *HandlerConfig)
+		ns             *corev1.Namespace
+		oldNs          *corev1.Namespace
+		reverted       bool
+		expectedStatus string
+		expectedReason string
+	}{
+		{
+			name:           "annotation wins on create",
+			configure:      func(cfg *HandlerConfig) { cfg.ConflictPolicy = ConflictAnnotationWins },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "annotation wins over an unchanged label",
+			configure:      func(cfg *HandlerConfig) { cfg.ConflictPolicy = ConflictAnnotationWins },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			oldNs:          newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "label moved to a stripped project",
+			configure:      func(cfg *HandlerConfig) { cfg.ProjectAccess = strip },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			oldNs:          newTestNamespace("legacy", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "label moved to a protected project",
+			configure:      func(cfg *HandlerConfig) { cfg.ProtectedProjects = []string{"platform"} },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			oldNs:          newTestNamespace("legacy", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusAllowed,
+		},
+		{
+			name:           "annotation set by the requester to a stripped project",
+			configure:      func(cfg *HandlerConfig) { cfg.ProjectAccess = strip },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			oldNs:          newTestNamespace("platform", ""),
+			reverted:       true,
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonProjectRefused,
+		},
+		{
+			name:           "annotation set by the requester to a protected project",
+			configure:      func(cfg *HandlerConfig) { cfg.ProtectedProjects = []string{"platform"} },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-xyz789"),
+			reverted:       true,
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonProjectRefused,
+		},
+		{
+			name:           "annotation changed along with a label moved to a stripped project",
+			configure:      func(cfg *HandlerConfig) { cfg.ProjectAccess = strip },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			oldNs:          newTestNamespace("legacy", "c-m-abc123:p-legacy"),
+			reverted:       true,
+			expectedStatus: metrics.StatusDenied,
+			expectedReason: metrics.ValidationReasonLabelMismatch,
+		},
+		{
+			name:           "dry run",
+			configure:      func(cfg *HandlerConfig) { cfg.DryRun = true },
+			ns:             newTestNamespace("platform", "c-m-abc123:p-other"),
+			expectedStatus: metrics.StatusDryRun,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.ValidationDenialsTotal.Reset()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			client := &mockRancherClient{
+				clusterID:        "c-m-abc123",
+				projectID:        "p-xyz789",
+				existingProjects: []string{"p-xyz789", "p-other"},
+			}
+			cfg := testHandlerConfig()
+			tt.configure(&cfg)
+			handler := NewHandler(client, logger, cfg)
+
+			review := createAdmissionReview(tt.ns, admissionv1.Create)
+			if tt.oldNs != nil {
+				review = createAdmissionReviewWithOld(tt.ns, tt.oldNs, admissionv1.Update)
+			}
+			review.Request.UserInfo.Username = "mallory"
+
+			// Mutation admits these namespaces, undoing annotations set by the requester
+			if response, _ := handler.mutate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger); !response.Allowed || (response.Patch != nil) != tt.reverted {
+				t.Fatalf("expected mutation to admit the namespace with reverted=%v, got allowed=%v and patch %s", tt.reverted, response.Allowed, response.Patch)
+			}
+
+			response, status := handler.validate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+			if status != tt.expectedStatus {
+				t.Errorf("expected status '%s', got '%s'", tt.expectedStatus, status)
+			}
+			if response.Allowed != (tt.expectedStatus != metrics.StatusDenied) {
+				t.Errorf("unexpected allowed=%v", response.Allowed)
+			}
+			if tt.expectedReason != "" {
+				if got := testutil.ToFloat64(metrics.ValidationDenialsTotal.WithLabelValues(tt.expectedReason)); got != 1 {
+					t.Errorf("expected 1 denial with reason %s, got %f", tt.expectedReason, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_LookupFailure(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		cfg := testHandlerConfig()
+		cfg.StrictMode = strict
+		handler := NewHandler(&mockRancherClient{clusterErr: fmt.Errorf("cluster not found")}, logger, cfg)
+
+		review := createAdmissionReview(newTestNamespace("", "c-m-abc123:p-xyz789"), admissionv1.Create)
+		response, _ := handler.validate(context.Background(), review.Request, clusterRef{name: "test-cluster"}, logger)
+
+		if response.Allowed == strict {
+			t.Errorf("strict=%v: unexpected allowed=%v", strict, response.Allowed)
+		}
+	}
+}
+
+func TestHandleValidate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := &mockRancherClient{clusterID: "c-m-abc123", existingProjects: []string{"p-xyz789"}}
+	handler := NewHandler(client, logger, testHandlerConfig())
+
+	body, _ := json.Marshal(createAdmissionReview(newTestNamespace("", "c-m-abc123:p-gone1"), admissionv1.Create))
+	req := httptest.NewRequest(http.MethodPost, "/validate/test-cluster", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleValidate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(w.Body.Bytes(), &review); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if review.Response.Allowed {
+		t.Error("expected namespace to be denied")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/validate/", nil)
+	w = httptest.NewRecorder()
+	handler.HandleValidate(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}